@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"sync"
 
 	"koda-shortlink/internal/config"
 	"koda-shortlink/internal/routers"
@@ -9,19 +10,34 @@ import (
 
 	_ "koda-shortlink/docs"
 
+	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func Handler(w http.ResponseWriter, r *http.Request) {
+var (
+	router    *gin.Engine
+	setupOnce sync.Once
+)
+
+// setup runs once per instance: warm invocations reuse the pool, the router
+// and the GeoIP database instead of opening them again on every request.
+func setup() {
 	godotenv.Load()
 
 	pg := config.InitDbConfig()
-	router := routers.InitRouter(pg)
+	router = routers.InitRouter(pg)
 
 	utils.InitRedis()
+	utils.InitShortCodeGenerator()
+	utils.InitGeoIP()
+	utils.InitMailer()
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+}
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	setupOnce.Do(setup)
 
 	router.ServeHTTP(w, r)
 }
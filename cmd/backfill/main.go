@@ -0,0 +1,69 @@
+// Command backfill rebuilds shortlink_daily_stats from the raw clicks.
+//
+//	go run ./cmd/backfill [-from 2024-01-01] [-to 2024-06-30]
+//
+// Without flags it covers every closed day since the first click. Today is
+// never rolled up since it is still receiving clicks.
+package main
+
+import (
+	"flag"
+	"koda-shortlink/internal/config"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/worker"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	fromFlag := flag.String("from", "", "first day to rebuild, YYYY-MM-DD (default: day of the first click)")
+	toFlag := flag.String("to", "", "last day to rebuild, YYYY-MM-DD (default: yesterday)")
+	flag.Parse()
+
+	godotenv.Load()
+	pg := config.InitDbConfig()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	to := today
+	if *toFlag != "" {
+		last, err := time.Parse("2006-01-02", *toFlag)
+		if err != nil {
+			log.Fatalf("Invalid -to: %v", err)
+		}
+		to = last.AddDate(0, 0, 1)
+	}
+	if to.After(today) {
+		to = today
+	}
+
+	var from time.Time
+	if *fromFlag != "" {
+		first, err := time.Parse("2006-01-02", *fromFlag)
+		if err != nil {
+			log.Fatalf("Invalid -from: %v", err)
+		}
+		from = first
+	} else {
+		first, err := models.GetFirstClickDay(pg)
+		if err != nil {
+			log.Fatalf("Failed to find the first click: %v", err)
+		}
+		if first == nil {
+			log.Println("No clicks to roll up")
+			return
+		}
+		from = *first
+	}
+
+	if !from.Before(to) {
+		log.Fatalf("Nothing to do: %s is not before %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	if err := worker.RollupRange(pg, from, to); err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+	log.Println("Backfill done")
+}
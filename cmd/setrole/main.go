@@ -0,0 +1,49 @@
+// Command setrole changes a user's role. It is how the first admin is
+// created, since registration always gives the user role.
+//
+//	go run ./cmd/setrole -email admin@example.com [-role admin]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"koda-shortlink/internal/config"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the user to change")
+	role := flag.String("role", models.RoleAdmin, "new role, user or admin")
+	flag.Parse()
+
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+	if !models.IsValidRole(*role) {
+		log.Fatalf("Invalid -role %q, must be user or admin", *role)
+	}
+
+	godotenv.Load()
+	pg := config.InitDbConfig()
+	utils.InitRedis()
+
+	id, err := models.SetUserRoleByEmail(pg, *email, *role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Fatalf("No user with email %s", *email)
+	}
+	if err != nil {
+		log.Fatalf("Failed to change role: %v", err)
+	}
+
+	// Outstanding access tokens still carry the old role.
+	if err := utils.RevokeUserTokens(context.Background(), id); err != nil {
+		log.Fatalf("User %d (%s) is now %s, but their access tokens could not be revoked: %v", id, *email, *role, err)
+	}
+	log.Printf("User %d (%s) is now %s", id, *email, *role)
+}
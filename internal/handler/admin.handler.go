@@ -0,0 +1,500 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// globalStatsCacheKey is dropped on every click and link change, like the
+// per-user dashboard caches.
+const globalStatsCacheKey = "analytics:global:7d"
+
+type AdminController struct {
+	DB *pgxpool.Pool
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+type SetUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// adminPage reads the page and limit query parameters.
+func adminPage(ctx *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	return page, limit
+}
+
+func paginationData(total, page, limit int) gin.H {
+	return gin.H{
+		"total": total,
+		"limit": limit,
+		"page":  page,
+		"pages": int(math.Ceil(float64(total) / float64(limit))),
+		"next":  page*limit < total,
+		"back":  page > 1,
+	}
+}
+
+// targetUser loads the :id user, writing the error response when it can't.
+// Admins cannot act on their own account, so they can't lock themselves out.
+func (ac *AdminController) targetUser(ctx *gin.Context) (models.AdminUser, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "User not found",
+		})
+		return models.AdminUser{}, false
+	}
+
+	if adminID, _ := ctx.Get("userID"); adminID == id {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "You cannot change your own account",
+		})
+		return models.AdminUser{}, false
+	}
+
+	user, err := models.GetAdminUser(ac.DB, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(404, response.Response{
+				Success: false,
+				Message: "User not found",
+			})
+		} else {
+			ctx.JSON(500, response.Response{
+				Success: false,
+				Message: "Failed to retrieve user",
+			})
+		}
+		return models.AdminUser{}, false
+	}
+
+	return user, true
+}
+
+// GetGlobalStats godoc
+// @Summary Get global statistics
+// @Description Totals over all users and links, the last 7 days of visits, and account and moderation counts (admin only).
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=object{stats=models.DashboardStats,overview=models.AdminOverview}} "Returns global statistics"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 500 {object} response.Response "Failed to retrieve statistics"
+// @Router /api/v1/admin/stats [get]
+func (ac *AdminController) GetGlobalStats(ctx *gin.Context) {
+	rctx := context.Background()
+
+	var stats models.DashboardStats
+	cached := false
+	if val, err := utils.RedisClient.Get(rctx, globalStatsCacheKey).Result(); err == nil && val != "" {
+		cached = json.Unmarshal([]byte(val), &stats) == nil
+	}
+
+	if !cached {
+		var err error
+		stats, err = models.GetDashboardStats(ac.DB)
+		if err != nil {
+			ctx.JSON(500, response.Response{
+				Success: false,
+				Message: "Failed to retrieve statistics",
+			})
+			return
+		}
+		jsonData, _ := json.Marshal(stats)
+		utils.RedisClient.Set(rctx, globalStatsCacheKey, jsonData, time.Hour)
+	}
+
+	overview, err := models.GetAdminOverview(ac.DB)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve statistics",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Global statistics retrieved successfully",
+		Data: gin.H{
+			"stats": gin.H{
+				"totalLinks":   stats.TotalLinks,
+				"totalVisits":  stats.TotalVisits,
+				"uniqueVisits": stats.UniqueVisits,
+				"avgClickRate": stats.AvgClickRate,
+				"visitsGrowth": stats.VisitsGrowth,
+				"last7Days":    stats.Last7Days,
+			},
+			"overview": overview,
+		},
+	})
+}
+
+// ListUsers godoc
+// @Summary List users
+// @Description List and search all users with their link and visit counts (admin only).
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Search in name and email"
+// @Param role query string false "user or admin"
+// @Param suspended query bool false "Only suspended (true) or active (false) accounts"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page, at most 100" default(20)
+// @Success 200 {object} response.Response{data=object{items=[]models.AdminUser}} "Returns the users"
+// @Failure 400 {object} response.Response "Invalid filter"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 500 {object} response.Response "Failed to retrieve users"
+// @Router /api/v1/admin/users [get]
+func (ac *AdminController) ListUsers(ctx *gin.Context) {
+	filter := models.AdminUserFilter{
+		Query: ctx.Query("q"),
+		Role:  ctx.Query("role"),
+	}
+
+	if filter.Role != "" && !models.IsValidRole(filter.Role) {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "role must be user or admin",
+		})
+		return
+	}
+
+	if s := ctx.Query("suspended"); s != "" {
+		suspended, err := strconv.ParseBool(s)
+		if err != nil {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "suspended must be true or false",
+			})
+			return
+		}
+		filter.Suspended = &suspended
+	}
+
+	page, limit := adminPage(ctx)
+	users, total, err := models.ListUsers(ac.DB, filter, limit, (page-1)*limit)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve users",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Users retrieved successfully",
+		Data: gin.H{
+			"items":      users,
+			"pagination": paginationData(total, page, limit),
+		},
+	})
+}
+
+// SuspendUser godoc
+// @Summary Suspend a user
+// @Description Lock a user out: their sessions are ended, login and token refresh are refused and their access tokens are rejected (admin only).
+// @Description Their links keep resolving; disable them separately if needed.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param body body SuspendUserRequest false "Reason for the suspension"
+// @Success 200 {object} response.Response{data=models.AdminUser} "User suspended"
+// @Failure 400 {object} response.Response "Cannot suspend yourself"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Failed to suspend user"
+// @Router /api/v1/admin/users/{id}/suspend [post]
+func (ac *AdminController) SuspendUser(ctx *gin.Context) {
+	var req SuspendUserRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "Invalid request body: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	user, ok := ac.targetUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := models.SuspendUser(ac.DB, user.ID, req.Reason); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to suspend user",
+		})
+		return
+	}
+	utils.MarkUserSuspended(context.Background(), user.ID, true)
+	utils.RevokeUserTokens(context.Background(), user.ID)
+
+	user, _ = models.GetAdminUser(ac.DB, user.ID)
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "User suspended successfully",
+		Data:    user,
+	})
+}
+
+// UnsuspendUser godoc
+// @Summary Lift a user's suspension
+// @Description Let a suspended user log in again (admin only).
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=models.AdminUser} "Suspension lifted"
+// @Failure 400 {object} response.Response "Cannot change your own account"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Failed to lift suspension"
+// @Router /api/v1/admin/users/{id}/suspend [delete]
+func (ac *AdminController) UnsuspendUser(ctx *gin.Context) {
+	user, ok := ac.targetUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := models.UnsuspendUser(ac.DB, user.ID); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to lift suspension",
+		})
+		return
+	}
+	utils.MarkUserSuspended(context.Background(), user.ID, false)
+
+	user, _ = models.GetAdminUser(ac.DB, user.ID)
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Suspension lifted successfully",
+		Data:    user,
+	})
+}
+
+// SetUserRole godoc
+// @Summary Change a user's role
+// @Description Promote a user to admin or demote them back to user (admin only).
+// @Description The new role applies to access tokens issued from then on, at the latest after the user's next token refresh.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param body body SetUserRoleRequest true "New role"
+// @Success 200 {object} response.Response{data=models.AdminUser} "Role changed"
+// @Failure 400 {object} response.Response "Invalid role or own account"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Failed to change role"
+// @Router /api/v1/admin/users/{id}/role [put]
+func (ac *AdminController) SetUserRole(ctx *gin.Context) {
+	var req SetUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if !models.IsValidRole(req.Role) {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "role must be user or admin",
+		})
+		return
+	}
+
+	user, ok := ac.targetUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := models.SetUserRole(ac.DB, user.ID, req.Role); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to change role",
+		})
+		return
+	}
+
+	// Outstanding access tokens still carry the old role.
+	utils.RevokeUserTokens(context.Background(), user.ID)
+
+	user.Role = req.Role
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Role changed successfully",
+		Data:    user,
+	})
+}
+
+// ListShortlinks godoc
+// @Summary List shortlinks
+// @Description List and search the shortlinks of all users, including anonymous ones (admin only).
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Search in short code and destination URL"
+// @Param status query string false "active, inactive or disabled"
+// @Param userId query int false "Only links of this user"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page, at most 100" default(20)
+// @Success 200 {object} response.Response{data=object{items=[]models.AdminShortlink}} "Returns the shortlinks"
+// @Failure 400 {object} response.Response "Invalid filter"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 500 {object} response.Response "Failed to retrieve shortlinks"
+// @Router /api/v1/admin/links [get]
+func (ac *AdminController) ListShortlinks(ctx *gin.Context) {
+	filter := models.AdminShortlinkFilter{
+		Query:  ctx.Query("q"),
+		Status: ctx.Query("status"),
+	}
+
+	if filter.Status != "" && filter.Status != "active" && filter.Status != "inactive" && filter.Status != models.ShortlinkStatusDisabled {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "status must be active, inactive or disabled",
+		})
+		return
+	}
+
+	if s := ctx.Query("userId"); s != "" {
+		userID, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "Invalid userId",
+			})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	page, limit := adminPage(ctx)
+	links, total, err := models.ListAllShortlinks(ac.DB, filter, limit, (page-1)*limit)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve shortlinks",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Shortlinks retrieved successfully",
+		Data: gin.H{
+			"items":      links,
+			"pagination": paginationData(total, page, limit),
+		},
+	})
+}
+
+// DisableShortlink godoc
+// @Summary Force-disable a shortlink
+// @Description Stop a shortlink from resolving. Its owner cannot re-enable it; only an admin can (admin only).
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Success 200 {object} response.Response{data=models.Shortlink} "Shortlink disabled"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 500 {object} response.Response "Failed to disable shortlink"
+// @Router /api/v1/admin/links/{shortCode}/disable [post]
+func (ac *AdminController) DisableShortlink(ctx *gin.Context) {
+	ac.setShortlinkStatus(ctx, models.ShortlinkStatusDisabled, "Shortlink disabled successfully")
+}
+
+// EnableShortlink godoc
+// @Summary Re-enable a shortlink
+// @Description Lift a forced disable; the link becomes active again (admin only).
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Success 200 {object} response.Response{data=models.Shortlink} "Shortlink enabled"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 500 {object} response.Response "Failed to enable shortlink"
+// @Router /api/v1/admin/links/{shortCode}/disable [delete]
+func (ac *AdminController) EnableShortlink(ctx *gin.Context) {
+	ac.setShortlinkStatus(ctx, "active", "Shortlink enabled successfully")
+}
+
+func (ac *AdminController) setShortlinkStatus(ctx *gin.Context, status, message string) {
+	shortCode := ctx.Param("shortCode")
+	sl, err := models.GetShortlinkByCode(ac.DB, shortCode)
+	if err != nil {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "Shortlink not found",
+		})
+		return
+	}
+
+	updated, err := models.SetShortlinkStatus(ac.DB, sl.ID, status)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to update shortlink",
+		})
+		return
+	}
+
+	rctx := context.Background()
+	utils.RedisClient.Del(rctx, "link:"+shortCode+":destination")
+	if updated.UserID != nil {
+		utils.InvalidateDashboardCache(rctx, *updated.UserID)
+	}
+
+	emitWebhookEvent(ac.DB, updated.UserID, models.WebhookEventLinkUpdated, updated)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: message,
+		Data:    updated,
+	})
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"fmt"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const maxAPIKeysPerUser = 20
+
+type APIKeyController struct {
+	DB *pgxpool.Pool
+}
+
+// CreateAPIKeyRequest leaves out scopes to grant all of them, and expiresAt
+// for a key that never expires.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// validateAPIKeyScopes returns the scopes without duplicates, or a message
+// naming the first unknown one.
+func validateAPIKeyScopes(scopes []string) ([]string, string) {
+	if len(scopes) == 0 {
+		return slices.Clone(models.APIKeyScopes), ""
+	}
+
+	out := []string{}
+	for _, s := range scopes {
+		if !slices.Contains(models.APIKeyScopes, s) {
+			return nil, fmt.Sprintf("Unknown scope %q, expected one of %s", s, strings.Join(models.APIKeyScopes, ", "))
+		}
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out, ""
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Mint a personal API key for scripts, sent as "Authorization: Bearer sk_..." on the shortlink and stats endpoints.
+// @Description Scopes are links:read, links:write and stats:read; all of them when left out. The key is only returned here; only its prefix is kept in clear.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateAPIKeyRequest true "API key payload"
+// @Success 201 {object} response.Response{data=object{key=string,apiKey=models.APIKey}} "API key created"
+// @Failure 400 {object} response.Response "Invalid request body, scope or expiry"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 409 {object} response.Response "API key limit reached"
+// @Failure 500 {object} response.Response "Failed to create API key"
+// @Router /api/v1/api-keys [post]
+func (kc *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	var req CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Name is required",
+		})
+		return
+	}
+
+	scopes, msg := validateAPIKeyScopes(req.Scopes)
+	if msg != "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: msg,
+		})
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "expiresAt must be in the future",
+		})
+		return
+	}
+
+	count, err := models.CountAPIKeysByUser(kc.DB, userID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create API key",
+		})
+		return
+	}
+	if count >= maxAPIKeysPerUser {
+		ctx.JSON(409, response.Response{
+			Success: false,
+			Message: fmt.Sprintf("At most %d API keys are allowed", maxAPIKeysPerUser),
+		})
+		return
+	}
+
+	key, hint, err := utils.GenerateAPIKey()
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create API key",
+		})
+		return
+	}
+
+	apiKey, err := models.CreateAPIKey(kc.DB, userID, name, hint, utils.HashToken(key), scopes, req.ExpiresAt)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create API key",
+		})
+		return
+	}
+
+	ctx.JSON(201, response.Response{
+		Success: true,
+		Message: "API key created successfully, copy it now as it won't be shown again",
+		Data: gin.H{
+			"key":    key,
+			"apiKey": apiKey,
+		},
+	})
+}
+
+// GetAPIKeys godoc
+// @Summary List API keys
+// @Description List the user's API keys with their scopes, expiry and when and from where they were last used.
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.APIKey} "Returns the API keys"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 500 {object} response.Response "Failed to retrieve API keys"
+// @Router /api/v1/api-keys [get]
+func (kc *APIKeyController) GetAPIKeys(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	keys, err := models.GetAPIKeysByUser(kc.DB, userID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve API keys",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "API keys retrieved successfully",
+		Data:    keys,
+	})
+}
+
+// DeleteAPIKey godoc
+// @Summary Revoke an API key
+// @Description Delete an API key; requests using it are refused from then on.
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} response.Response "API key revoked"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 404 {object} response.Response "API key not found"
+// @Failure 500 {object} response.Response "Failed to revoke API key"
+// @Router /api/v1/api-keys/{id} [delete]
+func (kc *APIKeyController) DeleteAPIKey(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "API key not found",
+		})
+		return
+	}
+
+	found, err := models.DeleteAPIKey(kc.DB, id, userID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to revoke API key",
+		})
+		return
+	}
+	if !found {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "API key not found",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "API key revoked successfully",
+	})
+}
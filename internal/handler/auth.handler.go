@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"koda-shortlink/internal/models"
 	"koda-shortlink/internal/utils"
 	"koda-shortlink/pkg/response"
+	"log"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,11 +20,12 @@ type AuthController struct {
 
 
 // @Summary Register a new user
-// @Description Register a new user with fullname, email, password, and optional role. Default role is 'user' if not provided.
+// @Description Register a new user with fullname, email and password. New users always get the 'user' role; admins are promoted through the admin API or the setrole command.
+// @Description A verification mail is sent to the address. Until it is verified the user can log in but not create links, unless EMAIL_VERIFICATION=optional.
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Param body body models.UserRegister true "User registration payload" example({"fullname":"John Doe","email":"[john@example.com](mailto:john@example.com)","password":"secret123","role":"user"})
+// @Param body body models.UserRegister true "User registration payload" example({"fullname":"John Doe","email":"[john@example.com](mailto:john@example.com)","password":"secret123"})
 // @Success 201 {object} response.Response "Returns the created user data"
 // @Failure 400 {object} response.Response "Invalid request body"
 // @Failure 409 {object} response.Response "Email already registered"
@@ -72,6 +76,7 @@ func (ac *AuthController) Register(ctx *gin.Context) {
 		return
 	}
 
+	sendVerificationMail(user)
 
 	ctx.JSON(201, response.Response{
 		Success: true,
@@ -90,6 +95,7 @@ func (ac *AuthController) Register(ctx *gin.Context) {
 // @Success 200 {object} response.Response{data=object{user=models.UserResponse,token=string,refreshToken=string}} "Returns user data, access token, and refresh token"
 // @Failure 400 {object} response.Response "Invalid request body"
 // @Failure 401 {object} response.Response "Email or password incorrect"
+// @Failure 403 {object} response.Response "Account suspended"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /api/v1/auth/login [post]
 func (ac *AuthController) Login(ctx *gin.Context) {
@@ -133,11 +139,18 @@ func (ac *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, err := utils.GenerateToken(int(user.ID), user.Email, user.Role)
+	_, suspended, err := models.GetUserAccess(ac.DB, user.ID)
 	if err != nil {
 		ctx.JSON(500, response.Response{
 			Success: false,
-			Message: "Failed to generate access token",
+			Message: "Failed to check account status",
+		})
+		return
+	}
+	if suspended {
+		ctx.JSON(403, response.Response{
+			Success: false,
+			Message: "Account suspended",
 		})
 		return
 	}
@@ -152,10 +165,10 @@ func (ac *AuthController) Login(ctx *gin.Context) {
 	}
 
 	expiresAt := time.Now().Add(7 * 24 * time.Hour)
-	err = models.CreateSession(
+	sessionID, err := models.CreateSession(
 		ac.DB,
 		int(user.ID),
-		refreshToken,
+		utils.HashToken(refreshToken),
 		ctx.GetHeader("User-Agent"),
 		ctx.ClientIP(),
 		expiresAt,
@@ -168,6 +181,15 @@ func (ac *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
+	accessToken, err := utils.GenerateToken(int(user.ID), user.Email, user.Role, sessionID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to generate access token",
+		})
+		return
+	}
+
 	user.Token = accessToken
 
 	ctx.JSON(200, response.Response{
@@ -186,11 +208,12 @@ func (ac *AuthController) Login(ctx *gin.Context) {
 // RefreshTokenRequest godoc
 // @Summary Refresh access token
 // @Description Refresh access token menggunakan refresh token yang tersimpan di server. Token baru hanya diberikan jika refresh token valid dan aktif di sessions table.
+// @Description Setiap refresh juga menghasilkan refresh token baru; refresh token lama tidak berlaku lagi. Jika refresh token lama dipakai ulang, seluruh sesi tersebut dicabut.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param body body object{refreshToken=string} true "Refresh Token payload"
-// @Success 200 {object} response.Response{data=object{token=string}} "Returns new access token"
+// @Success 200 {object} response.Response{data=object{token=string,refreshToken=string}} "Returns new access token and refresh token"
 // @Failure 400 {object} response.Response "Refresh token required"
 // @Failure 401 {object} response.Response "Invalid or expired refresh token"
 // @Failure 500 {object} response.Response "Failed to generate access token"
@@ -217,8 +240,35 @@ func (ac *AuthController) RefreshToken(ctx *gin.Context) {
 		return
 	}
 
-	session, err := models.GetSessionByToken(ac.DB, req.RefreshToken)
-	if err != nil || session.ExpiresAt.Before(time.Now()) {
+	newRefreshToken, err := utils.GenerateRefreshToken(claims.Id, claims.Email, claims.Role)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "failed to generate refresh token",
+		})
+		return
+	}
+
+	session, err := models.RotateSession(
+		ac.DB,
+		utils.HashToken(req.RefreshToken),
+		utils.HashToken(newRefreshToken),
+		ctx.GetHeader("User-Agent"),
+		ctx.ClientIP(),
+		time.Now().Add(7*24*time.Hour),
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrRefreshTokenReused) {
+			log.Printf("refresh token reused for user %d from %s, session family %s revoked", session.UserID, ctx.ClientIP(), session.FamilyID)
+			// The access tokens of the family may be in the wrong hands too.
+			utils.RevokeSessionTokens(ctx.Request.Context(), session.FamilyID)
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(500, response.Response{
+				Success: false,
+				Message: "failed to refresh session",
+			})
+			return
+		}
 		ctx.JSON(401, response.Response{
 			Success: false,
 			Message: "invalid or expired refresh token",
@@ -226,7 +276,18 @@ func (ac *AuthController) RefreshToken(ctx *gin.Context) {
 		return
 	}
 
-	newAccessToken, err := utils.GenerateToken(session.UserID, claims.Email, claims.Role)
+	// The role is read again so promotions, demotions and suspensions apply
+	// from the next refresh on.
+	role, suspended, err := models.GetUserAccess(ac.DB, int64(session.UserID))
+	if err != nil || suspended {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "invalid or expired refresh token",
+		})
+		return
+	}
+
+	newAccessToken, err := utils.GenerateToken(session.UserID, claims.Email, role, session.FamilyID)
 	if err != nil {
 		ctx.JSON(500, response.Response{
 			Success: false,
@@ -239,7 +300,8 @@ func (ac *AuthController) RefreshToken(ctx *gin.Context) {
 		Success: true,
 		Message: "token refreshed",
 		Data: gin.H{
-			"token": newAccessToken,
+			"token":        newAccessToken,
+			"refreshToken": newRefreshToken,
 		},
 	})
 }
@@ -248,7 +310,7 @@ func (ac *AuthController) RefreshToken(ctx *gin.Context) {
 
 // Logout godoc
 // @Summary Logout user
-// @Description Logout user dengan menghapus refresh token di server
+// @Description Logout user dengan menghapus refresh token di server. Access token dari sesi tersebut, dan yang dikirim di header Authorization, langsung dicabut.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -270,7 +332,7 @@ func (ac *AuthController) Logout(ctx *gin.Context) {
 		return
 	}
 
-	err := models.DeleteSession(ac.DB, req.RefreshToken)
+	sessionID, err := models.DeleteSession(ac.DB, utils.HashToken(req.RefreshToken))
 	if err != nil {
 		ctx.JSON(500, response.Response{
 			Success: false,
@@ -279,12 +341,74 @@ func (ac *AuthController) Logout(ctx *gin.Context) {
 		return
 	}
 
+	rctx := ctx.Request.Context()
+	if sessionID != "" {
+		utils.RevokeSessionTokens(rctx, sessionID)
+	}
+	if authHeader := ctx.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		if claims, err := utils.ParseAccessToken(strings.TrimPrefix(authHeader, "Bearer ")); err == nil {
+			utils.RevokeAccessToken(rctx, claims)
+		}
+	}
+
 	ctx.JSON(200, response.Response{
 		Success: true,
 		Message: "Logout successful",
 	})
 }
 
+// LogoutAll godoc
+// @Summary Logout from every device
+// @Description Hapus semua sesi user dan cabut semua access token yang sudah diterbitkan, termasuk yang dipakai untuk request ini.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Logged out everywhere"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 500 {object} response.Response "Failed to logout"
+// @Router /api/v1/auth/logout-all [post]
+func (ac *AuthController) LogoutAll(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	if err := models.DeleteUserSessions(ac.DB, userID); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "failed to logout",
+		})
+		return
+	}
+
+	if err := utils.RevokeUserTokens(ctx.Request.Context(), userID); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "failed to revoke access tokens",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Logged out from every device",
+	})
+}
+
 
 
 
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxBulkLinks          = 1000
+	maxAnonymousBulkLinks = 20
+	maxBulkCSVBytes       = 2 * 1024 * 1024
+	// maxBulkBodyBytes leaves room for the multipart framing around the CSV.
+	maxBulkBodyBytes = maxBulkCSVBytes + 64*1024
+)
+
+type BulkShortlinkRowResult struct {
+	Row         int    `json:"row"`
+	OriginalURL string `json:"original_url"`
+	ShortCode   string `json:"short_code,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CreateShortlinksBulk godoc
+// @Summary Create shortlinks in bulk
+// @Description Create up to 1000 shortlinks in one transaction, or up to 20 without authentication.
+// @Description The request body may be at most about 2 MB.
+// @Description Send either a JSON array of CreateShortlinkRequest or a multipart CSV upload in the "file" field.
+// @Description The CSV may start with a header row naming original_url, custom_alias, expires_at and max_clicks; without one the columns are url then alias.
+// @Description Every row gets its own result with the created short code or the reason it was rejected.
+// @Tags Shortlinks
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param body body []CreateShortlinkRequest false "Shortlinks to create"
+// @Param file formData file false "CSV file of shortlinks"
+// @Success 200 {object} response.Response{data=object{created=int,failed=int,results=[]BulkShortlinkRowResult}} "Per-row results"
+// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 403 {object} response.Response "Email address not verified"
+// @Failure 413 {object} response.Response "Request body too large"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/links/bulk [post]
+func (sc *ShortlinkController) CreateShortlinksBulk(ctx *gin.Context) {
+	var items []CreateShortlinkRequest
+	var rowErrs map[int]string
+	var err error
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBulkBodyBytes)
+
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		items, rowErrs, err = readBulkCSV(ctx)
+	} else {
+		err = json.NewDecoder(ctx.Request.Body).Decode(&items)
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(413, response.Response{
+			Success: false,
+			Message: fmt.Sprintf("Request body exceeds %d bytes", maxBulkBodyBytes),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if len(items) == 0 {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "No shortlinks to create",
+		})
+		return
+	}
+
+	var uid *int64
+	if userIDValue, exists := ctx.Get("userID"); exists {
+		switch v := userIDValue.(type) {
+		case int64:
+			uid = &v
+		case int:
+			id := int64(v)
+			uid = &id
+		case float64:
+			id := int64(v)
+			uid = &id
+		}
+	}
+
+	limit := maxBulkLinks
+	if uid == nil {
+		limit = maxAnonymousBulkLinks
+	}
+	if len(items) > limit {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: fmt.Sprintf("At most %d shortlinks can be created at once", limit),
+		})
+		return
+	}
+
+	if !requireVerifiedEmail(ctx, sc.DB, uid) {
+		return
+	}
+
+	results := make([]BulkShortlinkRowResult, len(items))
+	aliases := map[string]int{}
+	var links []models.Shortlink
+	var linkRows []int
+
+	for i, item := range items {
+		results[i] = BulkShortlinkRowResult{Row: i + 1, OriginalURL: item.OriginalURL}
+
+		if msg, ok := rowErrs[i]; ok {
+			results[i].Error = msg
+			continue
+		}
+
+		if msg := validateCreateShortlinkRequest(item); msg != "" {
+			results[i].Error = msg
+			continue
+		}
+
+		if item.CustomAlias != "" {
+			if row, dup := aliases[item.CustomAlias]; dup {
+				results[i].Error = fmt.Sprintf("Alias is already used by row %d", row)
+				continue
+			}
+			aliases[item.CustomAlias] = i + 1
+		}
+
+		links = append(links, models.Shortlink{
+			OriginalURL: item.OriginalURL,
+			ShortCode:   item.CustomAlias,
+			UserID:      uid,
+			ExpiresAt:   item.ExpiresAt,
+			MaxClicks:   item.MaxClicks,
+		})
+		linkRows = append(linkRows, i)
+	}
+
+	created := 0
+	if len(links) > 0 {
+		inserted, err := models.CreateShortlinksBulk(sc.DB, links, utils.ShortCodeGen)
+		if err != nil {
+			ctx.JSON(500, response.Response{
+				Success: false,
+				Message: "Failed to create shortlinks: " + err.Error(),
+			})
+			return
+		}
+
+		for j, res := range inserted {
+			row := &results[linkRows[j]]
+			switch {
+			case res.Err == nil:
+				row.ShortCode = res.Shortlink.ShortCode
+				created++
+				emitWebhookEvent(sc.DB, uid, models.WebhookEventLinkCreated, res.Shortlink)
+			case models.IsShortCodeConflict(res.Err):
+				row.Error = "Alias is already in use"
+			case errors.Is(res.Err, models.ErrShortCodeExhausted):
+				row.Error = "Could not generate a unique short code"
+			default:
+				row.Error = "Failed to create shortlink"
+			}
+		}
+	}
+
+	if uid != nil && created > 0 {
+		utils.InvalidateDashboardCache(context.Background(), *uid)
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: fmt.Sprintf("%d of %d shortlinks created", created, len(items)),
+		Data: gin.H{
+			"created": created,
+			"failed":  len(items) - created,
+			"results": results,
+		},
+	})
+}
+
+// readBulkCSV parses the uploaded CSV into create requests. Rows that can't
+// be parsed are returned in rowErrs keyed by their index in items.
+func readBulkCSV(ctx *gin.Context) ([]CreateShortlinkRequest, map[int]string, error) {
+	fileHeader, err := ctx.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, errors.New("CSV file is required in the \"file\" field")
+	}
+
+	if fileHeader.Size > maxBulkCSVBytes {
+		return nil, nil, fmt.Errorf("CSV file exceeds %d bytes", maxBulkCSVBytes)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns := map[string]int{"original_url": 0, "custom_alias": 1, "expires_at": -1, "max_clicks": -1}
+	var items []CreateShortlinkRequest
+	rowErrs := map[int]string{}
+
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if line == 0 && isBulkCSVHeader(record) {
+			columns = bulkCSVColumns(record)
+			continue
+		}
+
+		field := func(name string) string {
+			idx := columns[name]
+			if idx < 0 || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+
+		item := CreateShortlinkRequest{
+			OriginalURL: field("original_url"),
+			CustomAlias: field("custom_alias"),
+		}
+
+		if v := field("expires_at"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				rowErrs[len(items)] = "expires_at must be an RFC 3339 timestamp"
+			}
+			item.ExpiresAt = &t
+		}
+
+		if v := field("max_clicks"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				rowErrs[len(items)] = "max_clicks must be a number"
+			}
+			item.MaxClicks = &n
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rowErrs, nil
+}
+
+func isBulkCSVHeader(record []string) bool {
+	for _, cell := range record {
+		switch strings.ToLower(strings.TrimSpace(cell)) {
+		case "original_url", "url":
+			return true
+		}
+	}
+	return false
+}
+
+func bulkCSVColumns(header []string) map[string]int {
+	columns := map[string]int{"original_url": -1, "custom_alias": -1, "expires_at": -1, "max_clicks": -1}
+	for i, cell := range header {
+		switch strings.ToLower(strings.TrimSpace(cell)) {
+		case "original_url", "url":
+			columns["original_url"] = i
+		case "custom_alias", "alias":
+			columns["custom_alias"] = i
+		case "expires_at":
+			columns["expires_at"] = i
+		case "max_clicks":
+			columns["max_clicks"] = i
+		}
+	}
+	return columns
+}
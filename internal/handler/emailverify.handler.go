@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const defaultEmailVerificationTTL = 24 * time.Hour
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func emailVerificationTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL")); err == nil && d > 0 {
+		return d
+	}
+	return defaultEmailVerificationTTL
+}
+
+// emailVerificationRequired reports whether unverified users are kept from
+// creating links. EMAIL_VERIFICATION=optional still sends the mails but lets
+// everyone through.
+func emailVerificationRequired() bool {
+	return os.Getenv("EMAIL_VERIFICATION") != "optional"
+}
+
+func sendVerificationMail(user models.UserResponse) {
+	token, err := utils.GenerateEmailVerificationToken(user.ID, user.Email, emailVerificationTTL())
+	if err != nil {
+		log.Printf("email verification token for user %d: %v", user.ID, err)
+		return
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm that %s is your email address.\n\n", user.Fullname, user.Email)
+	if base := os.Getenv("VERIFY_EMAIL_URL"); base != "" {
+		body += fmt.Sprintf("Open this link to verify it:\n%s?token=%s\n\n", base, url.QueryEscape(token))
+	} else {
+		body += fmt.Sprintf("Your verification token:\n%s\n\n", token)
+	}
+	body += fmt.Sprintf("It expires in %d hours. If you didn't create an account, you can ignore this mail.\n",
+		int(emailVerificationTTL().Hours()))
+
+	utils.SendMailAsync(utils.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    body,
+	})
+}
+
+// requireVerifiedEmail answers 403 and returns false when uid belongs to a
+// user who hasn't verified their email yet and the policy requires it.
+// Anonymous requests pass.
+func requireVerifiedEmail(ctx *gin.Context, db *pgxpool.Pool, uid *int64) bool {
+	if uid == nil || !emailVerificationRequired() {
+		return true
+	}
+
+	verified, err := models.IsEmailVerified(db, *uid)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to check email verification",
+		})
+		return false
+	}
+	if !verified {
+		ctx.JSON(403, response.Response{
+			Success: false,
+			Message: "Verify your email address before creating links",
+		})
+		return false
+	}
+	return true
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Confirm the address with the token from the verification mail. The token is only valid for the address it was sent to.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body VerifyEmailRequest true "Verification token"
+// @Success 200 {object} response.Response "Email verified"
+// @Failure 400 {object} response.Response "Invalid or expired token"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/auth/verify-email [post]
+func (ac *AuthController) VerifyEmail(ctx *gin.Context) {
+	var req VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	claims, err := utils.VerifyEmailVerificationToken(req.Token)
+	if err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid or expired verification token",
+		})
+		return
+	}
+
+	already, err := models.MarkEmailVerified(ac.DB, claims.Id, claims.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "Invalid or expired verification token",
+			})
+			return
+		}
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to verify email",
+		})
+		return
+	}
+
+	if already {
+		ctx.JSON(200, response.Response{
+			Success: true,
+			Message: "Email already verified",
+		})
+		return
+	}
+
+	utils.RedisClient.Del(ctx.Request.Context(), "user:"+strconv.FormatInt(claims.Id, 10)+":profile")
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Email verified successfully",
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification mail
+// @Description Send a new verification mail to the authenticated user's address. Earlier tokens stay valid until they expire.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Verification mail sent"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 409 {object} response.Response "Email already verified"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/auth/resend-verification [post]
+func (ac *AuthController) ResendVerification(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	user, err := models.GetVerificationUser(ac.DB, userID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to load user",
+		})
+		return
+	}
+
+	if user.EmailVerified {
+		ctx.JSON(409, response.Response{
+			Success: false,
+			Message: "Email already verified",
+		})
+		return
+	}
+
+	sendVerificationMail(user)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Verification email sent",
+	})
+}
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/pkg/response"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const exportFlushEvery = 100
+
+// exportWriter writes records as CSV or NDJSON straight to the response.
+type exportWriter struct {
+	ctx    *gin.Context
+	format string
+	csv    *csv.Writer
+	json   *json.Encoder
+	rows   int
+}
+
+func newExportWriter(ctx *gin.Context, format, filename string, header []string) *exportWriter {
+	w := &exportWriter{ctx: ctx, format: format}
+
+	if format == "csv" {
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		w.csv = csv.NewWriter(ctx.Writer)
+		w.csv.Write(header)
+	} else {
+		ctx.Header("Content-Type", "application/x-ndjson")
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.ndjson"`, filename))
+		w.json = json.NewEncoder(ctx.Writer)
+	}
+	ctx.Status(200)
+
+	return w
+}
+
+func (w *exportWriter) write(record []string, value any) error {
+	var err error
+	if w.csv != nil {
+		err = w.csv.Write(record)
+	} else {
+		err = w.json.Encode(value)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%exportFlushEvery == 0 {
+		w.flush()
+	}
+	return nil
+}
+
+func (w *exportWriter) flush() {
+	if w.csv != nil {
+		w.csv.Flush()
+	}
+	w.ctx.Writer.Flush()
+}
+
+func exportFormat(ctx *gin.Context) (string, bool) {
+	format := ctx.DefaultQuery("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "format must be csv or ndjson",
+		})
+		return "", false
+	}
+	return format, true
+}
+
+// parseDateQuery accepts YYYY-MM-DD or RFC 3339. A bare date used as the
+// upper bound includes that whole day.
+func parseDateQuery(value string, upper bool) (*time.Time, error) {
+	return parseDateQueryIn(value, upper, time.UTC)
+}
+
+// parseDateQueryIn is parseDateQuery with bare dates taken as midnight in
+// loc. Results are returned in loc.
+func parseDateQueryIn(value string, upper bool, loc *time.Location) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		t = t.In(loc)
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return nil, err
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// ExportShortlinks godoc
+// @Summary Export shortlinks
+// @Description Stream all shortlinks of the authenticated user as CSV or NDJSON, including redirect counts, status and timestamps
+// @Tags Export
+// @Produce text/csv,application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "csv (default) or ndjson"
+// @Success 200 {file} file "Shortlinks export"
+// @Failure 400 {object} response.Response "Invalid format"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Router /api/v1/export/links [get]
+func (sc *ShortlinkController) ExportShortlinks(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+
+	w := newExportWriter(ctx, format, "shortlinks", []string{
+		"id", "short_code", "original_url", "status", "redirect_count",
+		"expires_at", "max_clicks", "protected", "created_at", "updated_at",
+	})
+
+	err := models.StreamShortlinksByUser(sc.DB, userID, func(sl models.Shortlink) error {
+		maxClicks := ""
+		if sl.MaxClicks != nil {
+			maxClicks = strconv.Itoa(*sl.MaxClicks)
+		}
+		return w.write([]string{
+			strconv.Itoa(sl.ID),
+			sl.ShortCode,
+			sl.OriginalURL,
+			sl.Status,
+			strconv.Itoa(sl.RedirectCount),
+			formatOptionalTime(sl.ExpiresAt),
+			maxClicks,
+			strconv.FormatBool(sl.Protected),
+			sl.CreatedAt.Format(time.RFC3339),
+			sl.UpdatedAt.Format(time.RFC3339),
+		}, sl)
+	})
+	w.flush()
+
+	if err != nil {
+		log.Printf("export shortlinks for user %d: %v", userID, err)
+		ctx.Abort()
+	}
+}
+
+// ExportClicks godoc
+// @Summary Export click history
+// @Description Stream the click history of the authenticated user's shortlinks as CSV or NDJSON, optionally limited to a date range
+// @Description Clicks older than the retention policy keeps (CLICK_RETENTION_MONTHS) are not included; a from date before them is refused.
+// @Tags Export
+// @Produce text/csv,application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "csv (default) or ndjson"
+// @Param from query string false "Start date, YYYY-MM-DD or RFC 3339"
+// @Param to query string false "End date (inclusive for YYYY-MM-DD), YYYY-MM-DD or RFC 3339"
+// @Success 200 {file} file "Clicks export"
+// @Failure 400 {object} response.Response "Invalid format or date, or from before the clicks kept"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 500 {object} response.Response "Failed to export clicks"
+// @Router /api/v1/export/clicks [get]
+func (sc *ShortlinkController) ExportClicks(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+
+	from, err := parseDateQuery(ctx.Query("from"), false)
+	if err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid from date",
+		})
+		return
+	}
+
+	to, err := parseDateQuery(ctx.Query("to"), true)
+	if err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid to date",
+		})
+		return
+	}
+
+	if from != nil {
+		var retired *models.RawClicksRetiredError
+		if err := models.CheckRawClicksKept(sc.DB, *from); errors.As(err, &retired) {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: fmt.Sprintf("Clicks before %s are no longer kept, only their daily totals", retired.From.Format("2006-01-02")),
+			})
+			return
+		} else if err != nil {
+			ctx.JSON(500, response.Response{
+				Success: false,
+				Message: "Failed to export clicks",
+			})
+			return
+		}
+	}
+
+	w := newExportWriter(ctx, format, "clicks", []string{
+		"id", "short_code", "ip_address", "user_agent", "referrer_domain", "browser", "os", "device", "country_code", "region_code", "city", "is_bot", "clicked_at",
+	})
+
+	err = models.StreamClicksByUser(sc.DB, userID, from, to, func(click models.ExportedClick) error {
+		return w.write([]string{
+			strconv.Itoa(click.ID),
+			click.ShortCode,
+			click.IP,
+			click.UserAgent,
+			click.ReferrerDomain,
+			click.Browser,
+			click.OS,
+			click.Device,
+			click.CountryCode,
+			click.RegionCode,
+			click.City,
+			strconv.FormatBool(click.IsBot),
+			click.ClickedAt.Format(time.RFC3339),
+		}, click)
+	})
+	w.flush()
+
+	if err != nil {
+		log.Printf("export clicks for user %d: %v", userID, err)
+		ctx.Abort()
+	}
+}
@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	unlockTokenTTL      = 30 * time.Minute
+	maxUnlockAttempts   = 5
+	unlockAttemptWindow = 15 * time.Minute
+)
+
+var passwordChallengePage = template.Must(template.New("challenge").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Protected link</title></head>
+<body>
+<form method="POST" action="/{{.}}/unlock">
+<p>This link is password protected.</p>
+<input type="password" name="password" autofocus required>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>`))
+
+type ShortlinkPasswordRequest struct {
+	Password string `json:"password" form:"password" binding:"required,min=4"`
+}
+
+func unlockCookieName(shortCode string) string {
+	return "unlock_" + shortCode
+}
+
+// hasUnlockToken accepts the token from the cookie set by UnlockShortlink
+// or the X-Unlock-Token header. It is never read from the URL, where it
+// would end up in access logs and Referer headers.
+func hasUnlockToken(ctx *gin.Context, shortCode string) bool {
+	token, _ := ctx.Cookie(unlockCookieName(shortCode))
+	if token == "" {
+		token = ctx.GetHeader("X-Unlock-Token")
+	}
+	return token != "" && utils.VerifyUnlockToken(token, shortCode)
+}
+
+func servePasswordChallenge(ctx *gin.Context, shortCode string) {
+	if ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		var buf bytes.Buffer
+		if err := passwordChallengePage.Execute(&buf, shortCode); err == nil {
+			ctx.Data(401, "text/html; charset=utf-8", buf.Bytes())
+			return
+		}
+	}
+
+	ctx.JSON(401, response.Response{
+		Success: false,
+		Message: "This shortlink is password protected",
+		Data: gin.H{
+			"protected": true,
+			"unlockUrl": "/" + shortCode + "/unlock",
+		},
+	})
+}
+
+// SetShortlinkPassword godoc
+// @Summary Set shortlink password
+// @Description Protect a shortlink with a password. Visitors must unlock it before being redirected (requires authentication)
+// @Tags Shortlinks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Param body body ShortlinkPasswordRequest true "Password payload"
+// @Success 200 {object} response.Response "Password set successfully"
+// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission to update this link"
+// @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 500 {object} response.Response "Failed to set password"
+// @Router /api/v1/links/{shortCode}/password [put]
+func (sc *ShortlinkController) SetShortlinkPassword(ctx *gin.Context) {
+	var req ShortlinkPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	sl, ok := sc.ownedShortlink(ctx, "update")
+	if !ok {
+		return
+	}
+
+	hashed, err := utils.HashPassword(req.Password)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to hash password",
+		})
+		return
+	}
+
+	if err := models.SetShortlinkPassword(sc.DB, sl.ID, &hashed); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to set password",
+		})
+		return
+	}
+
+	utils.RedisClient.Del(context.Background(), "link:"+sl.ShortCode+":destination")
+	sl.Protected = true
+	emitWebhookEvent(sc.DB, sl.UserID, models.WebhookEventLinkUpdated, sl)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Password set successfully",
+	})
+}
+
+// RemoveShortlinkPassword godoc
+// @Summary Remove shortlink password
+// @Description Remove password protection from a shortlink (requires authentication)
+// @Tags Shortlinks
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Success 200 {object} response.Response "Password removed successfully"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission to update this link"
+// @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 500 {object} response.Response "Failed to remove password"
+// @Router /api/v1/links/{shortCode}/password [delete]
+func (sc *ShortlinkController) RemoveShortlinkPassword(ctx *gin.Context) {
+	sl, ok := sc.ownedShortlink(ctx, "update")
+	if !ok {
+		return
+	}
+
+	if err := models.SetShortlinkPassword(sc.DB, sl.ID, nil); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to remove password",
+		})
+		return
+	}
+
+	utils.RedisClient.Del(context.Background(), "link:"+sl.ShortCode+":destination")
+	sl.Protected = false
+	emitWebhookEvent(sc.DB, sl.UserID, models.WebhookEventLinkUpdated, sl)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Password removed successfully",
+	})
+}
+
+// UnlockShortlink godoc
+// @Summary Unlock a password-protected shortlink
+// @Description Check the password of a protected shortlink and issue a short-lived unlock token.
+// @Description The token is set as a cookie and also returned for API clients, which can send it as X-Unlock-Token.
+// @Description Form posts are redirected back to the shortlink. At most 5 attempts per IP and link are allowed every 15 minutes; a correct password resets the count.
+// @Tags Redirect
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Param shortCode path string true "Short code"
+// @Param body body ShortlinkPasswordRequest true "Password payload"
+// @Success 200 {object} response.Response{data=object{token=string,expiresIn=int}} "Unlock token issued"
+// @Success 303 {string} string "Redirects back to the shortlink"
+// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 401 {object} response.Response "Wrong password"
+// @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 429 {object} response.Response "Too many failed attempts"
+// @Router /{shortCode}/unlock [post]
+func (sc *ShortlinkController) UnlockShortlink(ctx *gin.Context) {
+	shortCode := ctx.Param("shortCode")
+	rctx := context.Background()
+	attemptsKey := fmt.Sprintf("ratelimit:%s:unlock:%s", ctx.ClientIP(), shortCode)
+
+	// Every attempt is counted before the password is checked, so parallel
+	// guesses can't all slip under the limit; a correct password clears it.
+	attempts, err := utils.RateLimitHit(rctx, attemptsKey, unlockAttemptWindow)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	if attempts > maxUnlockAttempts {
+		ctx.JSON(429, response.Response{
+			Success: false,
+			Message: "Too many failed attempts, try again later",
+		})
+		return
+	}
+
+	var req ShortlinkPasswordRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	hash, err := models.GetShortlinkPasswordHash(sc.DB, shortCode)
+	if err != nil {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "Shortlink not found",
+		})
+		return
+	}
+
+	if hash == "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "This shortlink is not password protected",
+		})
+		return
+	}
+
+	ok, err := utils.VerifyPassword(req.Password, hash)
+	if err != nil || !ok {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "Password incorrect",
+		})
+		return
+	}
+
+	utils.RedisClient.Del(rctx, attemptsKey)
+
+	token, err := utils.GenerateUnlockToken(shortCode, unlockTokenTTL)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to generate unlock token",
+		})
+		return
+	}
+
+	ctx.SetCookie(unlockCookieName(shortCode), token, int(unlockTokenTTL.Seconds()), "/"+shortCode, "", ctx.Request.TLS != nil, true)
+
+	if ctx.ContentType() == gin.MIMEPOSTForm {
+		ctx.Redirect(303, "/"+shortCode)
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Shortlink unlocked",
+		Data: gin.H{
+			"token":     token,
+			"expiresIn": int(unlockTokenTTL.Seconds()),
+		},
+	})
+}
+
+// ownedShortlink loads the :shortCode link and makes sure it belongs to the
+// authenticated user, writing the error response when it doesn't.
+func (sc *ShortlinkController) ownedShortlink(ctx *gin.Context, action string) (models.Shortlink, bool) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return models.Shortlink{}, false
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	sl, err := models.GetShortlinkByCode(sc.DB, ctx.Param("shortCode"))
+	if err != nil {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "Shortlink not found",
+		})
+		return models.Shortlink{}, false
+	}
+
+	if sl.UserID == nil || *sl.UserID != userID {
+		ctx.JSON(403, response.Response{
+			Success: false,
+			Message: "You don't have permission to " + action + " this link",
+		})
+		return models.Shortlink{}, false
+	}
+
+	return sl, true
+}
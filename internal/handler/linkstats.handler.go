@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxStatsBuckets = 1000
+	linkStatsTTL    = 5 * time.Minute
+)
+
+var statsGranularities = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+// statsRange resolves the from/to/granularity query parameters. The default
+// window ends with the current day (or hour) so the cache key stays stable
+// while the window is open.
+func statsRange(ctx *gin.Context) (time.Time, time.Time, string, string) {
+	return statsRangeIn(ctx, time.UTC)
+}
+
+// statsRangeIn is statsRange with days starting at midnight in loc.
+func statsRangeIn(ctx *gin.Context, loc *time.Location) (time.Time, time.Time, string, string) {
+	granularity := ctx.DefaultQuery("granularity", "day")
+	unit, ok := statsGranularities[granularity]
+	if !ok {
+		return time.Time{}, time.Time{}, "", "granularity must be hour, day or week"
+	}
+
+	fromQ, err := parseDateQueryIn(ctx.Query("from"), false, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", "Invalid from date"
+	}
+	toQ, err := parseDateQueryIn(ctx.Query("to"), true, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", "Invalid to date"
+	}
+
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	if granularity == "hour" {
+		to = now.Truncate(time.Hour).Add(time.Hour)
+	}
+	if toQ != nil {
+		to = *toQ
+	}
+
+	from := to.AddDate(0, 0, -7)
+	switch granularity {
+	case "hour":
+		from = to.Add(-24 * time.Hour)
+	case "week":
+		from = to.AddDate(0, 0, -12*7)
+	}
+	if fromQ != nil {
+		from = *fromQ
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, "", "from must be before to"
+	}
+	if to.Sub(from)/unit > maxStatsBuckets {
+		return time.Time{}, time.Time{}, "", fmt.Sprintf("Range is too large for %s granularity", granularity)
+	}
+
+	return from, to, granularity, ""
+}
+
+// retiredRangeMessage explains a models.RawClicksRetiredError, or returns an
+// empty string for any other error.
+func retiredRangeMessage(err error) string {
+	var retired *models.RawClicksRetiredError
+	if !errors.As(err, &retired) {
+		return ""
+	}
+	return fmt.Sprintf("Clicks before %s are only kept as daily totals, use day or week granularity in UTC for earlier ranges",
+		retired.From.Format("2006-01-02"))
+}
+
+// includeBotsQuery reads the include_bots flag; bot clicks are hidden from
+// analytics by default.
+func includeBotsQuery(ctx *gin.Context) bool {
+	include, _ := strconv.ParseBool(ctx.Query("include_bots"))
+	return include
+}
+
+// GetShortlinkStats godoc
+// @Summary Get shortlink statistics
+// @Description Click time series, total and unique clicks, HyperLogLog unique visitor estimates and top user agents of a single shortlink (owner only).
+// @Description Defaults to the last 7 days by day, the last 24 hours by hour or the last 12 weeks by week.
+// @Description Bot and crawler clicks are excluded unless include_bots is true.
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Param from query string false "Start date, YYYY-MM-DD or RFC 3339"
+// @Param to query string false "End date (inclusive for YYYY-MM-DD), YYYY-MM-DD or RFC 3339"
+// @Param granularity query string false "hour, day (default) or week"
+// @Param include_bots query bool false "Include clicks classified as bots"
+// @Success 200 {object} response.Response{data=models.LinkStats} "Returns shortlink statistics"
+// @Failure 400 {object} response.Response "Invalid range or granularity, or an hourly range before the raw clicks kept"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission to view this link"
+// @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 500 {object} response.Response "Failed to retrieve shortlink stats"
+// @Router /api/v1/links/{shortCode}/stats [get]
+func (sc *ShortlinkController) GetShortlinkStats(ctx *gin.Context) {
+	sl, ok := sc.ownedShortlink(ctx, "view")
+	if !ok {
+		return
+	}
+
+	from, to, granularity, msg := statsRange(ctx)
+	if msg != "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: msg,
+		})
+		return
+	}
+
+	includeBots := includeBotsQuery(ctx)
+
+	rctx := context.Background()
+	cacheKey := fmt.Sprintf("analytics:link:%d:%s:%d:%d:%t", sl.ID, granularity, from.Unix(), to.Unix(), includeBots)
+
+	val, err := utils.RedisClient.Get(rctx, cacheKey).Result()
+	if err == nil && val != "" {
+		var stats models.LinkStats
+		if err := json.Unmarshal([]byte(val), &stats); err == nil {
+			ctx.JSON(200, response.Response{
+				Success: true,
+				Message: "Shortlink stats retrieved successfully (from cache)",
+				Data:    stats,
+			})
+			return
+		}
+	}
+
+	stats, err := models.GetLinkStats(sc.DB, sl, from, to, granularity, includeBots)
+	if msg := retiredRangeMessage(err); msg != "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: msg,
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve shortlink stats",
+		})
+		return
+	}
+
+	jsonData, _ := json.Marshal(stats)
+	utils.RedisClient.Set(rctx, cacheKey, jsonData, linkStatsTTL)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Shortlink stats retrieved successfully",
+		Data:    stats,
+	})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"koda-shortlink/internal/worker"
+	"koda-shortlink/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsController struct{}
+
+// GetClickPipelineStats godoc
+// @Summary Get click pipeline metrics
+// @Description Queue depth, throughput and backpressure counters of the asynchronous click pipeline (admin only).
+// @Description blocked counts enqueues that had to wait for room, overflow counts clicks written synchronously because the queue stayed full.
+// @Tags Metrics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=worker.ClickPipelineStats} "Returns click pipeline metrics"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission"
+// @Failure 503 {object} response.Response "Click pipeline is not running"
+// @Router /api/v1/metrics/clicks [get]
+func (mc *MetricsController) GetClickPipelineStats(ctx *gin.Context) {
+	if worker.Clicks == nil {
+		ctx.JSON(503, response.Response{
+			Success: false,
+			Message: "Click pipeline is not running",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Click pipeline metrics retrieved successfully",
+		Data:    worker.Clicks.Stats(),
+	})
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultResetTokenTTL = 30 * time.Minute
+	resetOTPDigits       = 6
+	maxResetOTPAttempts  = 5
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest takes either the token from the reset link, or the
+// email together with the OTP from the same mail.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Email    string `json:"email"`
+	OTP      string `json:"otp"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func resetTokenTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("RESET_TOKEN_TTL")); err == nil && d > 0 {
+		return d
+	}
+	return defaultResetTokenTTL
+}
+
+func resetAttemptsKey(userID int64) string {
+	return "auth:reset:" + strconv.FormatInt(userID, 10) + ":attempts"
+}
+
+func passwordResetMail(user models.UserResponse, token, otp string, ttl time.Duration) utils.MailMessage {
+	body := fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account.\n\n", user.Fullname)
+	if base := os.Getenv("RESET_PASSWORD_URL"); base != "" {
+		body += fmt.Sprintf("Open this link to choose a new password:\n%s?token=%s\n\n", base, url.QueryEscape(token))
+	} else {
+		body += fmt.Sprintf("Your reset token:\n%s\n\n", token)
+	}
+	body += fmt.Sprintf("Or enter this code: %s\n\nBoth expire in %d minutes. If you didn't ask for this, you can ignore this mail.\n",
+		otp, int(ttl.Minutes()))
+
+	return utils.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    body,
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Mail a one-time reset link and a 6-digit code to the address, valid for RESET_TOKEN_TTL (30 minutes by default).
+// @Description The answer is the same whether or not the email is registered. A new request replaces the previous one.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordRequest true "Account email"
+// @Success 200 {object} response.Response "Reset mail sent if the account exists"
+// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/auth/forgot-password [post]
+func (ac *AuthController) ForgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create reset token",
+		})
+		return
+	}
+	otp, err := utils.RandomDigits(resetOTPDigits)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create reset token",
+		})
+		return
+	}
+
+	ttl := resetTokenTTL()
+	user, err := models.StartPasswordReset(ac.DB, req.Email, utils.HashToken(token), utils.HashScopedToken(req.Email, otp), ttl)
+	switch {
+	case err == nil:
+		utils.RedisClient.Del(context.Background(), resetAttemptsKey(user.ID))
+		utils.SendMailAsync(passwordResetMail(user, token, otp, ttl))
+	case !errors.Is(err, pgx.ErrNoRows):
+		log.Printf("password reset for %s: %v", req.Email, err)
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to start password reset",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "If the email is registered, a reset link has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset the password
+// @Description Set a new password with the token from the reset link, or with the email and the 6-digit code.
+// @Description A code is void after 5 wrong attempts. On success every session of the user is ended and their access tokens are revoked.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body ResetPasswordRequest true "Token or email and code, and the new password"
+// @Success 200 {object} response.Response "Password reset"
+// @Failure 400 {object} response.Response "Invalid request body, or invalid or expired token"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/auth/reset-password [post]
+func (ac *AuthController) ResetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Token == "" && (req.Email == "" || req.OTP == "") {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Either token, or email and otp, are required",
+		})
+		return
+	}
+
+	var userID int64
+	var tokenHash, otpHash string
+	var err error
+	if req.Token != "" {
+		tokenHash = utils.HashToken(req.Token)
+		userID, err = models.FindPasswordResetByToken(ac.DB, tokenHash)
+	} else {
+		otpHash = utils.HashScopedToken(req.Email, req.OTP)
+		userID, err = ac.checkResetOTP(req.Email, otpHash)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "Invalid or expired reset token",
+			})
+			return
+		}
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to reset password",
+		})
+		return
+	}
+
+	hashed, err := utils.HashPassword(req.Password)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to hash password",
+		})
+		return
+	}
+
+	// Another request may have used the reset while the password was hashed.
+	if err := models.CompletePasswordReset(ac.DB, userID, tokenHash, otpHash, hashed); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "Invalid or expired reset token",
+			})
+			return
+		}
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to reset password",
+		})
+		return
+	}
+	utils.RedisClient.Del(context.Background(), resetAttemptsKey(userID))
+	utils.RevokeUserTokens(context.Background(), userID)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Password reset successfully, please log in again",
+	})
+}
+
+// checkResetOTP returns the user whose hashed reset code matches. Wrong codes count
+// against the reset, which is voided once maxResetOTPAttempts is reached;
+// every failure is reported as pgx.ErrNoRows.
+func (ac *AuthController) checkResetOTP(email, otpHash string) (int64, error) {
+	userID, match, err := models.FindPasswordResetByEmail(ac.DB, email, otpHash)
+	if err != nil {
+		return 0, err
+	}
+	if match {
+		return userID, nil
+	}
+
+	rctx := context.Background()
+	key := resetAttemptsKey(userID)
+	attempts, err := utils.RedisClient.Incr(rctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	utils.RedisClient.Expire(rctx, key, resetTokenTTL())
+
+	if attempts >= maxResetOTPAttempts {
+		if err := models.ClearPasswordReset(ac.DB, userID); err != nil {
+			return 0, err
+		}
+		utils.RedisClient.Del(rctx, key)
+	}
+	return 0, pgx.ErrNoRows
+}
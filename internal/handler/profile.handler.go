@@ -107,7 +107,7 @@ func (pc *ProfileController) GetProfile(ctx *gin.Context) {
 	jsonProfile, _ := json.Marshal(profile)
 	_ = utils.RedisClient.Set(rctx, profileCacheKey, jsonProfile, time.Hour)
 
-	stats, err := models.GetDashboardStatsByUser(pc.DB, userID)
+	stats, err := models.GetDashboardStatsByUser(pc.DB, userID, models.LastDaysRange(7, time.UTC))
 	if err == nil {
 		jsonStats, _ := json.Marshal(stats)
 		_ = utils.RedisClient.Set(rctx, statsCacheKey, jsonStats, time.Hour)
@@ -127,6 +127,7 @@ func (pc *ProfileController) GetProfile(ctx *gin.Context) {
 // UpdateProfile godoc
 // @Summary Update user profile
 // @Description Partially update user profile information (fullname, email, image in Base64 string) with Redis cache invalidation
+// @Description Changing the email marks it unverified and sends a verification mail to the new address.
 // @Tags Profile
 // @Accept json
 // @Produce json
@@ -175,8 +176,13 @@ func (pc *ProfileController) UpdateProfile(ctx *gin.Context) {
 		return
 	}
 
-	fullname := ctx.PostForm("fullname")
-	email := ctx.PostForm("email")
+	var fullname, email *string
+	if v := ctx.PostForm("fullname"); v != "" {
+		fullname = &v
+	}
+	if v := ctx.PostForm("email"); v != "" {
+		email = &v
+	}
 
 	var image *string
 	file, err := ctx.FormFile("image")
@@ -213,7 +219,8 @@ func (pc *ProfileController) UpdateProfile(ctx *gin.Context) {
 		image = &imageURL
 	}
 
-	if err := models.UpdateUserProfile(pc.DB, userID, &fullname, &email, image); err != nil {
+	emailChanged, err := models.UpdateUserProfile(pc.DB, userID, fullname, email, image)
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, response.Response{
 			Success: false,
 			Message: "Failed to update profile",
@@ -221,13 +228,19 @@ func (pc *ProfileController) UpdateProfile(ctx *gin.Context) {
 		return
 	}
 
+	if emailChanged {
+		if user, err := models.GetVerificationUser(pc.DB, int64(userID)); err == nil {
+			sendVerificationMail(user)
+		}
+	}
+
 	userIDStr := strconv.Itoa(userID)
 	profileCacheKey := "user:" + userIDStr + ":profile"
 	statsCacheKey := "user:" + userIDStr + ":stats"
 	_ = utils.RedisClient.Del(rctx, profileCacheKey, statsCacheKey)
 
 	profile, _ := models.GetUserProfile(pc.DB, userID)
-	stats, _ := models.GetDashboardStatsByUser(pc.DB, userID)
+	stats, _ := models.GetDashboardStatsByUser(pc.DB, userID, models.LastDaysRange(7, time.UTC))
 
 	ctx.JSON(http.StatusOK, response.Response{
 		Success: true,
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionController struct {
+	DB *pgxpool.Pool
+}
+
+// GetSessions godoc
+// @Summary List active sessions
+// @Description List the devices signed in to the account, most recently used first.
+// @Description lastUsedAt is the last token refresh; current marks the session of the calling access token.
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.ActiveSession} "Active sessions"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 500 {object} response.Response "Failed to retrieve sessions"
+// @Router /api/v1/sessions [get]
+func (sc *SessionController) GetSessions(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	sessions, err := models.ListActiveSessions(sc.DB, userID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve sessions",
+		})
+		return
+	}
+
+	current := ctx.GetString("sessionID")
+	for i := range sessions {
+		ua := ""
+		if sessions[i].UserAgent != nil {
+			ua = *sessions[i].UserAgent
+		}
+		info := utils.ParseUserAgent(ua)
+		sessions[i].Browser = info.Browser
+		sessions[i].OS = info.OS
+		sessions[i].Device = info.Device
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Sessions retrieved successfully",
+		Data:    sessions,
+	})
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Sign a device out by ending its session; its refresh token and access tokens stop working at once.
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.Response "Session revoked"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 404 {object} response.Response "Session not found"
+// @Failure 500 {object} response.Response "Failed to revoke session"
+// @Router /api/v1/sessions/{id} [delete]
+func (sc *SessionController) RevokeSession(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	sessionID := ctx.Param("id")
+	found, err := models.RevokeSession(sc.DB, userID, sessionID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to revoke session",
+		})
+		return
+	}
+	if !found {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "Session not found",
+		})
+		return
+	}
+	utils.RevokeSessionTokens(ctx.Request.Context(), sessionID)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke all other sessions
+// @Description Sign out every device except the one making the request. Their access tokens stop working at once.
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=object{revoked=int}} "Number of sessions revoked"
+// @Failure 400 {object} response.Response "Current session unknown, log in again"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 500 {object} response.Response "Failed to revoke sessions"
+// @Router /api/v1/sessions/others [delete]
+func (sc *SessionController) RevokeOtherSessions(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	// Access tokens issued before sessions were tracked don't say which
+	// session they belong to, so there is nothing to keep.
+	current := ctx.GetString("sessionID")
+	if current == "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Current session unknown, please log in again",
+		})
+		return
+	}
+
+	revoked, err := models.RevokeOtherSessions(sc.DB, userID, current)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to revoke sessions",
+		})
+		return
+	}
+	utils.RevokeSessionTokens(ctx.Request.Context(), revoked...)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Other sessions revoked successfully",
+		Data:    gin.H{"revoked": len(revoked)},
+	})
+}
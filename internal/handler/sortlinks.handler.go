@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"koda-shortlink/internal/models"
 	"koda-shortlink/internal/utils"
+	"koda-shortlink/internal/worker"
 	"koda-shortlink/pkg/response"
 	"math"
 	"strconv"
@@ -20,18 +21,69 @@ type ShortlinkController struct {
 }
 
 type CreateShortlinkRequest struct {
-	OriginalURL string `json:"original_url" binding:"required,url"`
+	OriginalURL string     `json:"original_url" binding:"required,url"`
+	CustomAlias string     `json:"custom_alias"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxClicks   *int       `json:"max_clicks"`
+}
+
+// validateCreateShortlinkRequest returns the reason req can't be created, or
+// an empty string when it is fine. Alias availability is checked separately.
+func validateCreateShortlinkRequest(req CreateShortlinkRequest) string {
+	if !utils.ValidateURL(req.OriginalURL) {
+		return "URL is not valid or unsupported"
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return "expires_at must be in the future"
+	}
+
+	if req.MaxClicks != nil && *req.MaxClicks <= 0 {
+		return "max_clicks must be greater than 0"
+	}
+
+	if req.CustomAlias != "" {
+		if !utils.IsValidShortCode(req.CustomAlias) {
+			return "Alias must be alphanumeric only"
+		}
+
+		if !utils.IsValidShortCodeLength(req.CustomAlias) {
+			return fmt.Sprintf("Alias must be between %d and %d characters", utils.MinShortCodeLength, utils.MaxShortCodeLength)
+		}
+
+		if utils.IsReservedShortCode(req.CustomAlias) {
+			return "Alias is reserved"
+		}
+	}
+
+	return ""
+}
+
+// destinationCacheTTL keeps the link:<code>:destination entry from
+// outliving the link's own expiry.
+func destinationCacheTTL(sl models.Shortlink) time.Duration {
+	ttl := 24 * time.Hour
+	if sl.ExpiresAt != nil {
+		if untilExpiry := time.Until(*sl.ExpiresAt); untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+	return ttl
 }
 
 // @Summary Create a new shortlink
-// @Description Generate a shortlink for the provided URL (works with or without authentication)
+// @Description Generate a shortlink for the provided URL (works with or without authentication).
+// @Description An optional custom_alias can be given to pick the short code instead of a random one.
+// @Description expires_at and max_clicks optionally limit how long and how often the link resolves.
 // @Tags Shortlinks
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param body body CreateShortlinkRequest true "Shortlink creation payload"
 // @Success 201 {object} response.Response "Returns the created shortlink data"
-// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 400 {object} response.Response "Invalid request body or alias"
+// @Failure 403 {object} response.Response "Email address not verified"
+// @Failure 409 {object} response.Response "Alias already in use"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /api/v1/links [post]
 func (sc *ShortlinkController) CreateShortlink(ctx *gin.Context) {
@@ -45,15 +97,32 @@ func (sc *ShortlinkController) CreateShortlink(ctx *gin.Context) {
 		return
 	}
 
-	if !utils.ValidateURL(req.OriginalURL) {
+	if msg := validateCreateShortlinkRequest(req); msg != "" {
 		ctx.JSON(400, gin.H{
 			"success": false,
-			"message": "URL is not valid or unsupported",
+			"message": msg,
 		})
 		return
 	}
 
-	shortCode := utils.GenerateShortCode(6)
+	if req.CustomAlias != "" {
+		exists, err := models.CheckShortCodeExists(sc.DB, req.CustomAlias)
+		if err != nil {
+			ctx.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to check alias availability",
+			})
+			return
+		}
+
+		if exists {
+			ctx.JSON(409, gin.H{
+				"success": false,
+				"message": "Alias is already in use",
+			})
+			return
+		}
+	}
 
 	var uid *int64
 	if userIDValue, exists := ctx.Get("userID"); exists {
@@ -70,14 +139,33 @@ func (sc *ShortlinkController) CreateShortlink(ctx *gin.Context) {
 	}
 	fmt.Println(uid)
 
+	if !requireVerifiedEmail(ctx, sc.DB, uid) {
+		return
+	}
+
 	sl := models.Shortlink{
 		OriginalURL: req.OriginalURL,
-		ShortCode:   shortCode,
+		ShortCode:   req.CustomAlias,
 		UserID:      uid,
+		ExpiresAt:   req.ExpiresAt,
+		MaxClicks:   req.MaxClicks,
 	}
 
-	newSL, err := models.CreateShortlink(sc.DB, sl)
+	var newSL models.Shortlink
+	var err error
+	if req.CustomAlias != "" {
+		newSL, err = models.CreateShortlink(sc.DB, sl)
+	} else {
+		newSL, err = models.CreateShortlinkWithGenerator(sc.DB, sl, utils.ShortCodeGen)
+	}
 	if err != nil {
+		if models.IsShortCodeConflict(err) {
+			ctx.JSON(409, gin.H{
+				"success": false,
+				"message": "Alias is already in use",
+			})
+			return
+		}
 		ctx.JSON(500, gin.H{
 			"success": false,
 			"message": "Failed to create shortlink: " + err.Error(),
@@ -86,10 +174,9 @@ func (sc *ShortlinkController) CreateShortlink(ctx *gin.Context) {
 	}
 
 	if uid != nil {
-		rctx := context.Background()
-		dashboardKey := fmt.Sprintf("analytics:user:%d:7d", *uid)
-		utils.RedisClient.Del(rctx, dashboardKey)
+		utils.InvalidateDashboardCache(context.Background(), *uid)
 	}
+	emitWebhookEvent(sc.DB, uid, models.WebhookEventLinkCreated, newSL)
 
 	ctx.JSON(201, gin.H{
 		"success": true,
@@ -99,6 +186,8 @@ func (sc *ShortlinkController) CreateShortlink(ctx *gin.Context) {
 			"original_url": newSL.OriginalURL,
 			"short_code":   newSL.ShortCode,
 			"status":       newSL.Status,
+			"expires_at":   newSL.ExpiresAt,
+			"max_clicks":   newSL.MaxClicks,
 			"created_at":   newSL.CreatedAt,
 		},
 	})
@@ -187,7 +276,9 @@ func (sc *ShortlinkController) GetAllShortlinks(ctx *gin.Context) {
 // @Produce json
 // @Param shortCode path string true "Shortlink code"
 // @Success 302 {string} string "Redirects to the original URL"
+// @Failure 401 {object} response.Response "Shortlink is password protected"
 // @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 410 {object} response.Response "Shortlink has expired"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /api/v1/links/{shortCode} [get]
 func (sc *ShortlinkController) GetShortlinkByCode(ctx *gin.Context) {
@@ -202,6 +293,14 @@ func (sc *ShortlinkController) GetShortlinkByCode(ctx *gin.Context) {
 		return
 	}
 
+	if sl.Status == models.ShortlinkStatusDisabled {
+		ctx.JSON(403, response.Response{
+			Success: false,
+			Message: "This shortlink has been disabled by an administrator",
+		})
+		return
+	}
+
 	if sl.Status == "inactive" {
 		ctx.JSON(403, response.Response{
 			Success: false,
@@ -210,7 +309,21 @@ func (sc *ShortlinkController) GetShortlinkByCode(ctx *gin.Context) {
 		return
 	}
 
-	if err := models.IncrementRedirectCount(sc.DB, sl.ID); err != nil {
+	if sl.IsExpired(time.Now()) {
+		ctx.JSON(410, response.Response{
+			Success: false,
+			Message: "This shortlink has expired",
+		})
+		return
+	}
+
+	if sl.Protected && !hasUnlockToken(ctx, shortCode) {
+		servePasswordChallenge(ctx, shortCode)
+		return
+	}
+
+	total, counted, err := models.ConsumeClick(sc.DB, sl.ID)
+	if err != nil {
 		ctx.JSON(500, response.Response{
 			Success: false,
 			Message: "Failed to increment redirect count",
@@ -218,25 +331,58 @@ func (sc *ShortlinkController) GetShortlinkByCode(ctx *gin.Context) {
 		return
 	}
 
-	click := models.ShortlinkClick{
-		ShortlinkID: sl.ID,
-		IP:          ctx.ClientIP(),
-		UserAgent:   ctx.Request.UserAgent(),
+	if !counted {
+		ctx.JSON(410, response.Response{
+			Success: false,
+			Message: "This shortlink has expired",
+		})
+		return
 	}
-	_ = models.LogClick(sc.DB, click)
+
+	click := models.NewShortlinkClick(sl.ID, ctx.Request.Method, ctx.ClientIP(), ctx.Request.UserAgent(), ctx.Request.Referer())
+	worker.RecordClick(sc.DB, worker.ClickEvent{
+		Click:     click,
+		ShortCode: sl.ShortCode,
+		OwnerID:   sl.UserID,
+		Counted:   true,
+		Total:     total,
+	})
 
 	ctx.Redirect(302, sl.OriginalURL)
 }
 
 type UpdateShortlinkRequest struct {
-	OriginalURL string `json:"originalUrl" binding:"required"`
-	ShortCode   string `json:"shortCode"`
-	Status      string `json:"status"`
+	OriginalURL string       `json:"originalUrl" binding:"required"`
+	ShortCode   string       `json:"shortCode"`
+	Status      string       `json:"status"`
+	ExpiresAt   NullableTime `json:"expiresAt" swaggertype:"string"`
+	MaxClicks   *int         `json:"maxClicks"`
+}
+
+// NullableTime tells a field sent as null apart from one left out: Set is
+// true whenever the field was in the body, and Time is nil for null.
+type NullableTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (n *NullableTime) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Time = nil
+		return nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	n.Time = &t
+	return nil
 }
 
 // UpdateShortlink godoc
 // @Summary Update shortlink
-// @Description Update original URL or generate/set new short code (requires authentication)
+// @Description Update original URL, expiry (a future date, or null to remove it) or click budget (maxClicks 0 removes it), or generate/set new short code (requires authentication)
 // @Tags Shortlinks
 // @Accept json
 // @Produce json
@@ -244,7 +390,7 @@ type UpdateShortlinkRequest struct {
 // @Param shortCode path string true "Existing short code"
 // @Param body body UpdateShortlinkRequest true "Update shortlink payload"
 // @Success 200 {object} response.Response "Shortlink updated successfully"
-// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 400 {object} response.Response "Invalid request body, status or expiry"
 // @Failure 401 {object} response.Response "User not authenticated"
 // @Failure 403 {object} response.Response "No permission to update this link"
 // @Failure 404 {object} response.Response "Shortlink not found"
@@ -304,12 +450,52 @@ func (sc *ShortlinkController) UpdateShortlink(ctx *gin.Context) {
 	}
 
 	if req.Status != "" {
+		if req.Status != "active" && req.Status != "inactive" {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "status must be active or inactive",
+			})
+			return
+		}
+		if sl.Status == models.ShortlinkStatusDisabled && req.Status != sl.Status {
+			ctx.JSON(403, response.Response{
+				Success: false,
+				Message: "This shortlink has been disabled by an administrator",
+			})
+			return
+		}
 		sl.Status = req.Status
 	}
 
-	if req.ShortCode == "" {
-		sl.ShortCode = utils.GenerateShortCode(6)
-	} else {
+	// null clears the expiry; a date must still be ahead.
+	if req.ExpiresAt.Set {
+		if req.ExpiresAt.Time != nil && !req.ExpiresAt.Time.After(time.Now()) {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "expiresAt must be in the future",
+			})
+			return
+		}
+		sl.ExpiresAt = req.ExpiresAt.Time
+	}
+
+	if req.MaxClicks != nil {
+		if *req.MaxClicks < 0 {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "maxClicks must not be negative",
+			})
+			return
+		}
+		// 0 removes the click budget.
+		if *req.MaxClicks == 0 {
+			sl.MaxClicks = nil
+		} else {
+			sl.MaxClicks = req.MaxClicks
+		}
+	}
+
+	if req.ShortCode != "" {
 		if !utils.IsValidShortCode(req.ShortCode) {
 			ctx.JSON(400, response.Response{
 				Success: false,
@@ -318,6 +504,22 @@ func (sc *ShortlinkController) UpdateShortlink(ctx *gin.Context) {
 			return
 		}
 
+		if !utils.IsValidShortCodeLength(req.ShortCode) {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: fmt.Sprintf("Short code must be between %d and %d characters", utils.MinShortCodeLength, utils.MaxShortCodeLength),
+			})
+			return
+		}
+
+		if utils.IsReservedShortCode(req.ShortCode) && req.ShortCode != sl.ShortCode {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "Short code is reserved",
+			})
+			return
+		}
+
 		exists, _ := models.CheckShortCodeExists(sc.DB, req.ShortCode)
 		if exists && req.ShortCode != sl.ShortCode {
 			ctx.JSON(409, response.Response{
@@ -330,8 +532,20 @@ func (sc *ShortlinkController) UpdateShortlink(ctx *gin.Context) {
 		sl.ShortCode = req.ShortCode
 	}
 
-	updatedSL, err := models.UpdateShortlink(sc.DB, sl)
+	var updatedSL models.Shortlink
+	if req.ShortCode == "" {
+		updatedSL, err = models.UpdateShortlinkWithGenerator(sc.DB, sl, utils.ShortCodeGen)
+	} else {
+		updatedSL, err = models.UpdateShortlink(sc.DB, sl)
+	}
 	if err != nil {
+		if models.IsShortCodeConflict(err) {
+			ctx.JSON(409, response.Response{
+				Success: false,
+				Message: "Short code is already in use",
+			})
+			return
+		}
 		ctx.JSON(500, response.Response{
 			Success: false,
 			Message: "Failed to update shortlink",
@@ -341,11 +555,11 @@ func (sc *ShortlinkController) UpdateShortlink(ctx *gin.Context) {
 
 	rctx := context.Background()
 	destKey := "link:" + shortCode + ":destination"
-	dashboardKey := fmt.Sprintf("analytics:user:%d:7d", userID)
 
 	utils.RedisClient.Del(rctx, destKey)
-	utils.RedisClient.Del(rctx, dashboardKey)
+	utils.InvalidateDashboardCache(rctx, userID)
 	utils.RedisClient.Del(rctx, "analytics:global:7d")
+	emitWebhookEvent(sc.DB, updatedSL.UserID, models.WebhookEventLinkUpdated, updatedSL)
 
 	ctx.JSON(200, response.Response{
 		Success: true,
@@ -417,11 +631,11 @@ func (sc *ShortlinkController) DeleteShortlink(ctx *gin.Context) {
 
 	rctx := context.Background()
 	destKey := "link:" + shortCode + ":destination"
-	dashboardKey := fmt.Sprintf("analytics:user:%d:7d", userID)
 
 	utils.RedisClient.Del(rctx, destKey)
-	utils.RedisClient.Del(rctx, dashboardKey)
+	utils.InvalidateDashboardCache(rctx, userID)
 	utils.RedisClient.Del(rctx, "analytics:global:7d")
+	emitWebhookEvent(sc.DB, sl.UserID, models.WebhookEventLinkDeleted, sl)
 
 	ctx.JSON(200, response.Response{
 		Success: true,
@@ -432,13 +646,19 @@ func (sc *ShortlinkController) DeleteShortlink(ctx *gin.Context) {
 // @Summary Resolve shortlink to original URL
 // @Description Resolve shortlink: hit Redis first, then DB fallback.
 // @Description Click counter is incremented in Redis. Analytics logged asynchronously.
+// @Description Password-protected links answer with a challenge until unlocked via /{shortCode}/unlock.
+// @Description An optional Bearer token records the logged-in viewer for the owner's viewer list; an invalid token is ignored.
+// @Description HEAD is answered the same way; it is what link checkers send, so those clicks are recorded as bots.
 // @Tags Redirect
 // @Produce json
 // @Param shortCode path string true "Short code"
 // @Success 200 {object} response.Response "Original URL returned successfully"
+// @Failure 401 {object} response.Response "Shortlink is password protected"
 // @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 410 {object} response.Response "Shortlink has expired or used up its click budget"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /{shortCode} [get]
+// @Router /{shortCode} [head]
 func (sc *ShortlinkController) GetShortlinksRedis(ctx *gin.Context) {
 	shortCode := ctx.Param("shortCode")
 	rctx := context.Background()
@@ -459,8 +679,18 @@ func (sc *ShortlinkController) GetShortlinksRedis(ctx *gin.Context) {
 			})
 			return
 		}
-		jsonData, _ := json.Marshal(sl)
-		utils.RedisClient.Set(rctx, destKey, jsonData, 24*time.Hour)
+		if ttl := destinationCacheTTL(sl); ttl > 0 {
+			jsonData, _ := json.Marshal(sl)
+			utils.RedisClient.Set(rctx, destKey, jsonData, ttl)
+		}
+	}
+
+	if sl.Status == models.ShortlinkStatusDisabled {
+		ctx.JSON(403, response.Response{
+			Success: false,
+			Message: "This shortlink has been disabled by an administrator",
+		})
+		return
 	}
 
 	if sl.Status == "inactive" {
@@ -471,6 +701,43 @@ func (sc *ShortlinkController) GetShortlinksRedis(ctx *gin.Context) {
 		return
 	}
 
+	if sl.IsExpired(time.Now()) {
+		utils.RedisClient.Del(rctx, destKey)
+		ctx.JSON(410, response.Response{
+			Success: false,
+			Message: "This shortlink has expired",
+		})
+		return
+	}
+
+	if sl.Protected && !hasUnlockToken(ctx, shortCode) {
+		servePasswordChallenge(ctx, shortCode)
+		return
+	}
+
+	// Links with a click budget are counted before redirecting so the
+	// budget cannot be overrun by concurrent visitors.
+	counted, total := false, 0
+	if sl.MaxClicks != nil {
+		n, ok, err := models.ConsumeClick(sc.DB, sl.ID)
+		if err != nil {
+			ctx.JSON(500, response.Response{
+				Success: false,
+				Message: "Failed to resolve shortlink",
+			})
+			return
+		}
+		if !ok {
+			utils.RedisClient.Del(rctx, destKey)
+			ctx.JSON(410, response.Response{
+				Success: false,
+				Message: "This shortlink has expired",
+			})
+			return
+		}
+		counted, total = true, n
+	}
+
 	if userIDValue, exists := ctx.Get("userID"); exists {
 		var userID int64
 		switch v := userIDValue.(type) {
@@ -482,40 +749,43 @@ func (sc *ShortlinkController) GetShortlinksRedis(ctx *gin.Context) {
 			userID = int64(v)
 		}
 
-		clickKey := fmt.Sprintf("link:%s:clicks:user:%d", shortCode, userID)
+		clickKey := worker.ViewerClicksKey(sl.ID, userID)
 		if err := utils.RedisClient.Incr(rctx, clickKey).Err(); err != nil {
 			fmt.Println("Redis Incr error:", err)
 		}
 	}
 
-	ctx.Redirect(302, sl.OriginalURL)
-
-	go func() {
-		if err := models.IncrementRedirectCount(sc.DB, sl.ID); err == nil {
-			rctx := context.Background()
-			if sl.UserID != nil {
-				dashboardKey := fmt.Sprintf("analytics:user:%d:7d", *sl.UserID)
-				utils.RedisClient.Del(rctx, dashboardKey)
-			}
+	// Everything is read from the request here; the click pipeline stores
+	// it later without touching the gin context.
+	click := models.NewShortlinkClick(sl.ID, ctx.Request.Method, ctx.ClientIP(), ctx.Request.UserAgent(), ctx.Request.Referer())
+	worker.RecordClick(sc.DB, worker.ClickEvent{
+		Click:     click,
+		ShortCode: sl.ShortCode,
+		OwnerID:   sl.UserID,
+		Counted:   counted,
+		Total:     total,
+	})
 
-			utils.RedisClient.Del(rctx, "analytics:global:7d")
-		}
-		_ = models.LogClick(sc.DB, models.ShortlinkClick{
-			ShortlinkID: sl.ID,
-			IP:          ctx.ClientIP(),
-			UserAgent:   ctx.Request.UserAgent(),
-		})
-	}()
+	ctx.Redirect(302, sl.OriginalURL)
 }
 
 // GetDashboardStats godoc
 // @Summary Get dashboard statistics
-// @Description Retrieve overall shortlink statistics for authenticated user
+// @Description Retrieve overall shortlink statistics for authenticated user. Bot and crawler clicks are excluded unless include_bots is true.
+// @Description totalVisits and uniqueVisits are all-time; rangeVisits, rangeUniqueVisits, visitsGrowth (against the preceding range of equal length) and series cover [from, to).
+// @Description Defaults to the last 7 days by day; bare dates and buckets follow tz.
+// @Description last7Days repeats series as {date, visits, uniqueVisits} for the default range and is empty otherwise.
 // @Tags Dashboard
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param from query string false "Start date, YYYY-MM-DD or RFC 3339"
+// @Param to query string false "End date (inclusive for YYYY-MM-DD), YYYY-MM-DD or RFC 3339"
+// @Param granularity query string false "hour, day (default) or week"
+// @Param tz query string false "IANA time zone, default UTC"
+// @Param include_bots query bool false "Include clicks classified as bots"
 // @Success 200 {object} response.Response{data=map[string]interface{}} "Returns dashboard statistics"
+// @Failure 400 {object} response.Response "Invalid range, granularity or tz, or an hourly or non-UTC range before the raw clicks kept"
 // @Failure 401 {object} response.Response "User not authenticated"
 // @Failure 500 {object} response.Response "Failed to retrieve dashboard stats"
 // @Router /api/v1/dashboard/stats [get]
@@ -546,8 +816,39 @@ func (sc *ShortlinkController) GetDashboardStats(ctx *gin.Context) {
 		return
 	}
 
+	loc := time.UTC
+	if tz := ctx.Query("tz"); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil || l == time.Local {
+			ctx.JSON(400, response.Response{
+				Success: false,
+				Message: "Invalid tz, expected an IANA time zone such as Asia/Jakarta",
+			})
+			return
+		}
+		loc = l
+	}
+
+	from, to, granularity, msg := statsRangeIn(ctx, loc)
+	if msg != "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: msg,
+		})
+		return
+	}
+
+	dashboardRange := models.DashboardRange{
+		From:        from,
+		To:          to,
+		Granularity: granularity,
+		Location:    loc,
+		IncludeBots: includeBotsQuery(ctx),
+	}
+
 	rctx := context.Background()
-	dashboardCacheKey := fmt.Sprintf("analytics:user:%d:7d", userID)
+	dashboardCacheKey := utils.DashboardCacheKey(rctx, userID, fmt.Sprintf("%s:%d:%d:%s:%t",
+		granularity, from.Unix(), to.Unix(), loc, dashboardRange.IncludeBots))
 
 	val, err := utils.RedisClient.Get(rctx, dashboardCacheKey).Result()
 	if err == nil && val != "" {
@@ -556,19 +857,20 @@ func (sc *ShortlinkController) GetDashboardStats(ctx *gin.Context) {
 			ctx.JSON(200, response.Response{
 				Success: true,
 				Message: "Dashboard stats retrieved successfully (from cache)",
-				Data: gin.H{
-					"totalLinks":   stats.TotalLinks,
-					"totalVisits":  stats.TotalVisits,
-					"avgClickRate": stats.AvgClickRate,
-					"visitsGrowth": stats.VisitsGrowth,
-					"last7Days":    stats.Last7Days,
-				},
+				Data:    dashboardStatsData(stats),
 			})
 			return
 		}
 	}
 
-	stats, err := models.GetDashboardStatsByUser(sc.DB, int(userID))
+	stats, err := models.GetDashboardStatsByUser(sc.DB, int(userID), dashboardRange)
+	if msg := retiredRangeMessage(err); msg != "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: msg,
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(500, response.Response{
 			Success: false,
@@ -583,13 +885,25 @@ func (sc *ShortlinkController) GetDashboardStats(ctx *gin.Context) {
 	ctx.JSON(200, response.Response{
 		Success: true,
 		Message: "Dashboard stats retrieved successfully",
-		Data: gin.H{
-			"totalLinks":   stats.TotalLinks,
-			"totalVisits":  stats.TotalVisits,
-			"avgClickRate": stats.AvgClickRate,
-			"visitsGrowth": stats.VisitsGrowth,
-			"last7Days":    stats.Last7Days,
-		},
+		Data:    dashboardStatsData(stats),
 	})
+}
 
+func dashboardStatsData(stats models.DashboardStats) gin.H {
+	return gin.H{
+		"from":              stats.From,
+		"to":                stats.To,
+		"granularity":       stats.Granularity,
+		"timezone":          stats.Timezone,
+		"totalLinks":        stats.TotalLinks,
+		"totalVisits":       stats.TotalVisits,
+		"uniqueVisits":      stats.UniqueVisits,
+		"rangeVisits":       stats.RangeVisits,
+		"rangeUniqueVisits": stats.RangeUniqueVisits,
+		"avgClickRate":      stats.AvgClickRate,
+		"visitsGrowth":      stats.VisitsGrowth,
+		"series":            stats.Series,
+		"last7Days":         stats.Last7Days,
+		"breakdowns":        stats.Breakdowns,
+	}
 }
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/pkg/response"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const clickStreamHeartbeat = 15 * time.Second
+
+var (
+	streamsDone      = make(chan struct{})
+	closeStreamsOnce sync.Once
+)
+
+// CloseStreams ends every open click stream. http.Server.Shutdown waits for
+// active requests, so it has to be registered with RegisterOnShutdown.
+func CloseStreams() {
+	closeStreamsOnce.Do(func() { close(streamsDone) })
+}
+
+// CreateStreamTicket godoc
+// @Summary Get a click stream ticket
+// @Description Exchange the Authorization header for a single-use ticket valid for 30 seconds, for clients such as the browser's EventSource that cannot send headers to /stream/clicks.
+// @Description The stream opened with it is authenticated as the token or API key that asked for the ticket.
+// @Tags Stream
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} response.Response{data=object{ticket=string,expiresIn=int}} "Ticket created"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 500 {object} response.Response "Failed to create stream ticket"
+// @Router /api/v1/stream/ticket [post]
+func (sc *ShortlinkController) CreateStreamTicket(ctx *gin.Context) {
+	if _, exists := ctx.Get("userID"); !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	credential := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	ticket, err := utils.NewStreamTicket(ctx.Request.Context(), credential)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create stream ticket",
+		})
+		return
+	}
+
+	ctx.JSON(201, response.Response{
+		Success: true,
+		Message: "Stream ticket created successfully",
+		Data: gin.H{
+			"ticket":    ticket,
+			"expiresIn": int(utils.StreamTicketTTL.Seconds()),
+		},
+	})
+}
+
+// StreamClicks godoc
+// @Summary Stream clicks in real time
+// @Description Server-Sent Events stream of clicks on the authenticated user's shortlinks, as they are stored by the click pipeline on any instance.
+// @Description Each `click` event carries the short code, time, country and device when known, and the link's running total.
+// @Description A `ping` comment is sent every 15 seconds to keep proxies from closing the connection. The stream ends with a `revoked` event once the access token is revoked.
+// @Description Browsers' EventSource cannot set headers, so they pass a ticket from POST /stream/ticket as the ticket query parameter instead.
+// @Tags Stream
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param ticket query string false "Single-use ticket from POST /stream/ticket, for clients that cannot send an Authorization header"
+// @Success 200 {object} utils.ClickStreamEvent "Stream of click events"
+// @Failure 401 {object} response.Response "User not authenticated or invalid ticket"
+// @Failure 500 {object} response.Response "Failed to subscribe to click stream"
+// @Router /api/v1/stream/clicks [get]
+func (sc *ShortlinkController) StreamClicks(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	// Requests authenticated with an API key carry no token claims.
+	var claims *utils.UserPayload
+	if v, ok := ctx.Get("tokenClaims"); ok {
+		claims, _ = v.(*utils.UserPayload)
+	}
+
+	reqCtx := ctx.Request.Context()
+	pubsub := utils.RedisClient.Subscribe(reqCtx, utils.ClickStreamChannel(userID))
+	defer pubsub.Close()
+
+	// Wait for the subscription to be confirmed so no click published after
+	// the response starts is missed.
+	if _, err := pubsub.Receive(reqCtx); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to subscribe to click stream",
+		})
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(200)
+	ctx.Writer.WriteString(": connected\n\n")
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(clickStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-reqCtx.Done():
+			return
+		case <-streamsDone:
+			return
+		case <-heartbeat.C:
+			// A stream outlives the check AuthMiddleware made when it was
+			// opened, so signing out or revoking the session must end it.
+			if claims != nil && utils.IsAccessTokenRevoked(reqCtx, claims) {
+				ctx.SSEvent("revoked", gin.H{"message": "Token revoked"})
+				ctx.Writer.Flush()
+				return
+			}
+			if _, err := ctx.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event utils.ClickStreamEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			ctx.SSEvent("click", event)
+			ctx.Writer.Flush()
+		}
+	}
+}
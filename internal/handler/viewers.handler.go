@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"koda-shortlink/internal/models"
+	"koda-shortlink/pkg/response"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetShortlinkViewers godoc
+// @Summary List users who opened a shortlink
+// @Description List the logged-in users who followed a shortlink with their click counts (owner only).
+// @Description Counts are collected in Redis and written to the database periodically, so the latest clicks may take a minute to appear.
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.Response{data=object{items=[]models.ViewerClick}} "Returns the viewers of the shortlink"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 403 {object} response.Response "No permission to view this link"
+// @Failure 404 {object} response.Response "Shortlink not found"
+// @Failure 500 {object} response.Response "Failed to retrieve viewers"
+// @Router /api/v1/links/{shortCode}/viewers [get]
+func (sc *ShortlinkController) GetShortlinkViewers(ctx *gin.Context) {
+	sl, ok := sc.ownedShortlink(ctx, "view")
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	viewers, total, err := models.GetShortlinkViewers(sc.DB, sl.ID, limit, (page-1)*limit)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve viewers",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Shortlink viewers retrieved successfully",
+		Data: gin.H{
+			"items": viewers,
+			"pagination": gin.H{
+				"total": total,
+				"limit": limit,
+				"page":  page,
+				"pages": int(math.Ceil(float64(total) / float64(limit))),
+				"next":  page*limit < total,
+				"back":  page > 1,
+			},
+		},
+	})
+}
@@ -0,0 +1,511 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"koda-shortlink/internal/worker"
+	"koda-shortlink/pkg/response"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const maxWebhooksPerUser = 10
+
+type WebhookController struct {
+	DB *pgxpool.Pool
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// emitWebhookEvent queues event for the user's webhooks subscribed to it.
+// It runs in the background; failures are logged and never reach the
+// request that caused the event.
+func emitWebhookEvent(db *pgxpool.Pool, userID *int64, event string, data any) {
+	if userID == nil {
+		return
+	}
+	go worker.EmitWebhookEvent(db, *userID, event, data)
+}
+
+// validateWebhook returns why url and events can't be used, or an empty
+// string. The URL must resolve to public addresses only.
+func validateWebhook(ctx context.Context, url string, events []string) string {
+	if !utils.ValidateURL(url) {
+		return "URL is not valid or unsupported"
+	}
+	if err := utils.CheckWebhookURL(ctx, url); err != nil {
+		if errors.Is(err, utils.ErrWebhookTargetNotAllowed) {
+			return "URL must point to a public address"
+		}
+		return "URL host could not be resolved"
+	}
+	if len(events) == 0 {
+		return "At least one event is required"
+	}
+	for _, event := range events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return fmt.Sprintf("Unknown event %q, must be one of %s", event, strings.Join(models.WebhookEvents, ", "))
+		}
+	}
+	return ""
+}
+
+// ownedWebhook loads the :id webhook of the authenticated user, writing the
+// error response when it can't.
+func (wc *WebhookController) ownedWebhook(ctx *gin.Context) (models.Webhook, bool) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return models.Webhook{}, false
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "Webhook not found",
+		})
+		return models.Webhook{}, false
+	}
+
+	w, err := models.GetWebhookByID(wc.DB, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(404, response.Response{
+				Success: false,
+				Message: "Webhook not found",
+			})
+		} else {
+			ctx.JSON(500, response.Response{
+				Success: false,
+				Message: "Failed to retrieve webhook",
+			})
+		}
+		return models.Webhook{}, false
+	}
+
+	return w, true
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook
+// @Description Subscribe a URL to link events: link.created, link.updated, link.deleted, link.clicked and link.milestone.
+// @Description Every delivery is a JSON POST of {id, event, createdAt, data} signed in the X-Webhook-Signature header as "t=<unix>,v1=<hex>", where v1 is the HMAC-SHA256 of "<t>.<body>" keyed with the webhook secret.
+// @Description The secret is only returned here and by GET /api/v1/webhooks/{id}. Failed deliveries are retried with exponential backoff.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateWebhookRequest true "Webhook payload"
+// @Success 201 {object} response.Response{data=models.Webhook} "Webhook created"
+// @Failure 400 {object} response.Response "Invalid request body, URL or event"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 409 {object} response.Response "Webhook limit reached"
+// @Failure 500 {object} response.Response "Failed to create webhook"
+// @Router /api/v1/webhooks [post]
+func (wc *WebhookController) CreateWebhook(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	var req CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if msg := validateWebhook(ctx.Request.Context(), req.URL, req.Events); msg != "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: msg,
+		})
+		return
+	}
+
+	count, err := models.CountWebhooksByUser(wc.DB, userID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create webhook",
+		})
+		return
+	}
+	if count >= maxWebhooksPerUser {
+		ctx.JSON(409, response.Response{
+			Success: false,
+			Message: fmt.Sprintf("At most %d webhooks are allowed", maxWebhooksPerUser),
+		})
+		return
+	}
+
+	secret, err := utils.GenerateWebhookSecret()
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create webhook",
+		})
+		return
+	}
+
+	w, err := models.CreateWebhook(wc.DB, models.Webhook{
+		UserID: userID,
+		URL:    req.URL,
+		Secret: secret,
+		Events: slices.Compact(slices.Sorted(slices.Values(req.Events))),
+		Active: true,
+	})
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to create webhook",
+		})
+		return
+	}
+
+	worker.ForgetWebhookSubscriptions(userID)
+
+	ctx.JSON(201, response.Response{
+		Success: true,
+		Message: "Webhook created successfully",
+		Data:    w,
+	})
+}
+
+// GetWebhooks godoc
+// @Summary List webhooks
+// @Description List the authenticated user's webhooks. Secrets are left out.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.Webhook} "Returns the webhooks"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 500 {object} response.Response "Failed to retrieve webhooks"
+// @Router /api/v1/webhooks [get]
+func (wc *WebhookController) GetWebhooks(ctx *gin.Context) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(401, response.Response{
+			Success: false,
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var userID int64
+	switch v := userIDValue.(type) {
+	case int64:
+		userID = v
+	case int:
+		userID = int64(v)
+	case float64:
+		userID = int64(v)
+	}
+
+	webhooks, err := models.GetWebhooksByUser(wc.DB, userID)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve webhooks",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Webhooks retrieved successfully",
+		Data:    webhooks,
+	})
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook
+// @Description Get one of the authenticated user's webhooks, including its signing secret.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.Response{data=models.Webhook} "Returns the webhook"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 404 {object} response.Response "Webhook not found"
+// @Failure 500 {object} response.Response "Failed to retrieve webhook"
+// @Router /api/v1/webhooks/{id} [get]
+func (wc *WebhookController) GetWebhook(ctx *gin.Context) {
+	w, ok := wc.ownedWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Webhook retrieved successfully",
+		Data:    w,
+	})
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Change the URL, events or active flag of a webhook. Omitted fields are left as they are.
+// @Description Deliveries queued for an inactive webhook wait until it is active again.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param body body UpdateWebhookRequest true "Webhook changes"
+// @Success 200 {object} response.Response{data=models.Webhook} "Webhook updated"
+// @Failure 400 {object} response.Response "Invalid request body, URL or event"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 404 {object} response.Response "Webhook not found"
+// @Failure 500 {object} response.Response "Failed to update webhook"
+// @Router /api/v1/webhooks/{id} [put]
+func (wc *WebhookController) UpdateWebhook(ctx *gin.Context) {
+	var req UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	w, ok := wc.ownedWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	if req.URL != "" {
+		w.URL = req.URL
+	}
+	if req.Events != nil {
+		w.Events = slices.Compact(slices.Sorted(slices.Values(req.Events)))
+	}
+	if req.Active != nil {
+		w.Active = *req.Active
+	}
+
+	if msg := validateWebhook(ctx.Request.Context(), w.URL, w.Events); msg != "" {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: msg,
+		})
+		return
+	}
+
+	updated, err := models.UpdateWebhook(wc.DB, w)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to update webhook",
+		})
+		return
+	}
+
+	worker.ForgetWebhookSubscriptions(w.UserID)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Webhook updated successfully",
+		Data:    updated,
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Delete a webhook together with its delivery log.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.Response "Webhook deleted"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 404 {object} response.Response "Webhook not found"
+// @Failure 500 {object} response.Response "Failed to delete webhook"
+// @Router /api/v1/webhooks/{id} [delete]
+func (wc *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	w, ok := wc.ownedWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	if _, err := models.DeleteWebhook(wc.DB, w.ID, w.UserID); err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to delete webhook",
+		})
+		return
+	}
+
+	worker.ForgetWebhookSubscriptions(w.UserID)
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Webhook deleted successfully",
+	})
+}
+
+// GetWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Delivery log of a webhook, newest first, with attempts, the last response and when the next retry is due.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param status query string false "pending, delivered or failed"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.Response{data=object{items=[]models.WebhookDelivery}} "Returns the deliveries"
+// @Failure 400 {object} response.Response "Invalid status"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 404 {object} response.Response "Webhook not found"
+// @Failure 500 {object} response.Response "Failed to retrieve deliveries"
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (wc *WebhookController) GetWebhookDeliveries(ctx *gin.Context) {
+	w, ok := wc.ownedWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	status := ctx.Query("status")
+	if status != "" && status != models.WebhookDeliveryPending && status != models.WebhookDeliveryDelivered && status != models.WebhookDeliveryFailed {
+		ctx.JSON(400, response.Response{
+			Success: false,
+			Message: "status must be pending, delivered or failed",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	deliveries, total, err := models.GetWebhookDeliveries(wc.DB, w.ID, status, limit, (page-1)*limit)
+	if err != nil {
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to retrieve deliveries",
+		})
+		return
+	}
+
+	ctx.JSON(200, response.Response{
+		Success: true,
+		Message: "Webhook deliveries retrieved successfully",
+		Data: gin.H{
+			"items": deliveries,
+			"pagination": gin.H{
+				"total": total,
+				"limit": limit,
+				"page":  page,
+				"pages": int(math.Ceil(float64(total) / float64(limit))),
+				"next":  page*limit < total,
+				"back":  page > 1,
+			},
+		},
+	})
+}
+
+// ReplayWebhookDelivery godoc
+// @Summary Replay a webhook delivery
+// @Description Queue a new delivery with the same event and payload as an earlier one. The original stays in the log and the new one points to it through replayOf.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} response.Response{data=models.WebhookDelivery} "Delivery queued"
+// @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 404 {object} response.Response "Webhook or delivery not found"
+// @Failure 500 {object} response.Response "Failed to replay delivery"
+// @Router /api/v1/webhooks/{id}/deliveries/{deliveryId}/replay [post]
+func (wc *WebhookController) ReplayWebhookDelivery(ctx *gin.Context) {
+	w, ok := wc.ownedWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(ctx.Param("deliveryId"), 10, 64)
+	if err != nil {
+		ctx.JSON(404, response.Response{
+			Success: false,
+			Message: "Delivery not found",
+		})
+		return
+	}
+
+	delivery, err := models.ReplayWebhookDelivery(wc.DB, w.ID, deliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(404, response.Response{
+				Success: false,
+				Message: "Delivery not found",
+			})
+			return
+		}
+		ctx.JSON(500, response.Response{
+			Success: false,
+			Message: "Failed to replay delivery",
+		})
+		return
+	}
+
+	ctx.JSON(202, response.Response{
+		Success: true,
+		Message: "Delivery queued for replay",
+		Data:    delivery,
+	})
+}
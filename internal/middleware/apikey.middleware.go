@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APIKeyMiddleware authenticates requests sending a personal API key as
+// "Authorization: Bearer sk_..." and requires the key to have scope. It sets
+// the same context keys as AuthMiddleware, plus apiKeyID. Any other request
+// passes through untouched, so it goes in front of AuthMiddleware or
+// OptAuthMiddleware, which skip requests a key already authenticated.
+func APIKeyMiddleware(db *pgxpool.Pool, scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if !strings.HasPrefix(key, utils.APIKeyPrefix) {
+			ctx.Next()
+			return
+		}
+
+		owner, err := models.GetAPIKeyOwner(db, utils.HashToken(key))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(500, gin.H{"success": false, "message": "Failed to check API key"})
+			ctx.Abort()
+			return
+		}
+		if err != nil || owner.Expired() {
+			ctx.JSON(401, gin.H{"success": false, "message": "Invalid or expired API key"})
+			ctx.Abort()
+			return
+		}
+
+		if owner.Suspended {
+			ctx.JSON(403, gin.H{"success": false, "message": "Account suspended"})
+			ctx.Abort()
+			return
+		}
+
+		if !owner.HasScope(scope) {
+			ctx.JSON(403, gin.H{"success": false, "message": "API key is missing the " + scope + " scope"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set("userID", owner.UserID)
+		ctx.Set("userEmail", owner.Email)
+		ctx.Set("userRole", owner.Role)
+		ctx.Set("apiKeyID", owner.KeyID)
+
+		ip := ctx.ClientIP()
+		go func() {
+			if err := models.TouchAPIKey(db, owner.KeyID, ip); err != nil {
+				log.Printf("api key %d: %v", owner.KeyID, err)
+			}
+		}()
+
+		ctx.Next()
+	}
+}
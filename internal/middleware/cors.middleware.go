@@ -14,7 +14,7 @@ func SetupCORS() gin.HandlerFunc {
 	config := cors.Config{
 		AllowOrigins:     []string{origin, "http://localhost:5173"},
 		AllowMethods:     []string{"GET","PATCH", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Unlock-Token"},
 		AllowCredentials: true,
 		MaxAge:           24 * time.Hour,
 	}
@@ -14,16 +14,12 @@ func RateLimitMiddleware(max int, window time.Duration) gin.HandlerFunc {
         endpoint := c.FullPath() 
         key := fmt.Sprintf("ratelimit:%s:%s", identity, endpoint)
 
-        count, err := utils.RedisClient.Incr(c, key).Result()
+        count, err := utils.RateLimitHit(c, key, window)
         if err != nil {
             c.AbortWithStatusJSON(500, gin.H{"error": "internal server error"})
             return
         }
 
-        if count == 1 {
-            utils.RedisClient.Expire(c, key, window)
-        }
-
         if count > int64(max) {
             c.AbortWithStatusJSON(429, gin.H{"error": "rate limit exceeded"})
             return
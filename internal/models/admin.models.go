@@ -0,0 +1,233 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// ShortlinkStatusDisabled marks a link switched off by an administrator.
+// Unlike inactive, its owner cannot turn it back on.
+const ShortlinkStatusDisabled = "disabled"
+
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+type AdminUser struct {
+	ID              int64      `json:"id"`
+	Fullname        string     `json:"fullname"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	SuspendedAt     *time.Time `json:"suspendedAt"`
+	SuspendedReason *string    `json:"suspendedReason"`
+	TotalLinks      int        `json:"totalLinks"`
+	TotalVisits     int        `json:"totalVisits"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+type AdminUserFilter struct {
+	Query     string
+	Role      string
+	Suspended *bool
+}
+
+type AdminShortlink struct {
+	Shortlink
+	OwnerEmail *string `json:"ownerEmail"`
+}
+
+type AdminShortlinkFilter struct {
+	Query  string
+	Status string
+	UserID *int64
+}
+
+const adminUserSelect = `SELECT u.id, u.fullname, u.email, u.role, u.email_verified_at, u.suspended_at, u.suspended_reason,
+	       COALESCE(l.links, 0), COALESCE(l.visits, 0), u.created_at, u.updated_at
+	FROM users u
+	LEFT JOIN LATERAL (
+	    SELECT COUNT(*) AS links, SUM(redirect_count) AS visits FROM shortlinks WHERE user_id = u.id
+	) l ON true`
+
+func scanAdminUser(row rowScanner) (AdminUser, error) {
+	var u AdminUser
+	err := row.Scan(&u.ID, &u.Fullname, &u.Email, &u.Role, &u.EmailVerifiedAt, &u.SuspendedAt, &u.SuspendedReason,
+		&u.TotalLinks, &u.TotalVisits, &u.CreatedAt, &u.UpdatedAt)
+	return u, err
+}
+
+// ListUsers searches users by name or email, newest first.
+func ListUsers(db *pgxpool.Pool, f AdminUserFilter, limit, offset int) ([]AdminUser, int, error) {
+	const where = ` WHERE ($1 = '' OR u.email ILIKE '%' || $1 || '%' OR u.fullname ILIKE '%' || $1 || '%')
+	   AND ($2 = '' OR u.role = $2)
+	   AND ($3::boolean IS NULL OR (u.suspended_at IS NOT NULL) = $3::boolean)`
+
+	var total int
+	err := db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM users u`+where,
+		f.Query, f.Role, f.Suspended,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(context.Background(),
+		adminUserSelect+where+` ORDER BY u.created_at DESC, u.id DESC LIMIT $4 OFFSET $5`,
+		f.Query, f.Role, f.Suspended, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []AdminUser{}
+	for rows.Next() {
+		u, err := scanAdminUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, u)
+	}
+	return users, total, rows.Err()
+}
+
+func GetAdminUser(db *pgxpool.Pool, id int64) (AdminUser, error) {
+	return scanAdminUser(db.QueryRow(context.Background(), adminUserSelect+` WHERE u.id = $1`, id))
+}
+
+// SuspendUser marks the user suspended and ends all their sessions.
+func SuspendUser(db *pgxpool.Pool, id int64, reason string) error {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var r *string
+	if reason != "" {
+		r = &reason
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE users SET suspended_at = COALESCE(suspended_at, now()), suspended_reason = $2, updated_at = now() WHERE id = $1`,
+		id, r,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func UnsuspendUser(db *pgxpool.Pool, id int64) error {
+	_, err := db.Exec(context.Background(),
+		`UPDATE users SET suspended_at = NULL, suspended_reason = NULL, updated_at = now() WHERE id = $1`,
+		id,
+	)
+	return err
+}
+
+func SetUserRole(db *pgxpool.Pool, id int64, role string) error {
+	_, err := db.Exec(context.Background(),
+		`UPDATE users SET role = $2, updated_at = now() WHERE id = $1`,
+		id, role,
+	)
+	return err
+}
+
+// SetUserRoleByEmail is SetUserRole for the command line. It returns
+// pgx.ErrNoRows when no user has the email.
+func SetUserRoleByEmail(db *pgxpool.Pool, email, role string) (int64, error) {
+	var id int64
+	err := db.QueryRow(context.Background(),
+		`UPDATE users SET role = $2, updated_at = now() WHERE email = $1 RETURNING id`,
+		email, role,
+	).Scan(&id)
+	return id, err
+}
+
+// ListAllShortlinks searches every link by short code or destination,
+// newest first.
+func ListAllShortlinks(db *pgxpool.Pool, f AdminShortlinkFilter, limit, offset int) ([]AdminShortlink, int, error) {
+	const where = ` WHERE ($1 = '' OR s.short_code ILIKE '%' || $1 || '%' OR s.original_url ILIKE '%' || $1 || '%')
+	   AND ($2 = '' OR s.status = $2)
+	   AND ($3::bigint IS NULL OR s.user_id = $3::bigint)`
+
+	var total int
+	err := db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM shortlinks s`+where,
+		f.Query, f.Status, f.UserID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(context.Background(),
+		`SELECT s.id, s.user_id, s.original_url, s.short_code, s.redirect_count, s.status, s.expires_at, s.max_clicks,
+		        s.password_hash IS NOT NULL, s.created_at, s.updated_at, u.email
+		 FROM shortlinks s
+		 LEFT JOIN users u ON u.id = s.user_id`+where+`
+		 ORDER BY s.created_at DESC, s.id DESC
+		 LIMIT $4 OFFSET $5`,
+		f.Query, f.Status, f.UserID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	links := []AdminShortlink{}
+	for rows.Next() {
+		var sl AdminShortlink
+		if err := rows.Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.Status, &sl.ExpiresAt, &sl.MaxClicks,
+			&sl.Protected, &sl.CreatedAt, &sl.UpdatedAt, &sl.OwnerEmail); err != nil {
+			return nil, 0, err
+		}
+		links = append(links, sl)
+	}
+	return links, total, rows.Err()
+}
+
+// SetShortlinkStatus changes a link's status regardless of its owner.
+func SetShortlinkStatus(db *pgxpool.Pool, id int, status string) (Shortlink, error) {
+	var sl Shortlink
+	err := db.QueryRow(context.Background(),
+		`UPDATE shortlinks SET status = $2, updated_at = now()
+		 WHERE id = $1
+		 RETURNING id, user_id, original_url, short_code, redirect_count, status, expires_at, max_clicks, password_hash IS NOT NULL, created_at, updated_at`,
+		id, status,
+	).Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.Status, &sl.ExpiresAt, &sl.MaxClicks, &sl.Protected, &sl.CreatedAt, &sl.UpdatedAt)
+	return sl, err
+}
+
+type AdminOverview struct {
+	TotalUsers     int `json:"totalUsers"`
+	AdminUsers     int `json:"adminUsers"`
+	SuspendedUsers int `json:"suspendedUsers"`
+	DisabledLinks  int `json:"disabledLinks"`
+}
+
+func GetAdminOverview(db *pgxpool.Pool) (AdminOverview, error) {
+	var o AdminOverview
+	err := db.QueryRow(context.Background(),
+		`SELECT COUNT(*),
+		        COUNT(*) FILTER (WHERE role = 'admin'),
+		        COUNT(*) FILTER (WHERE suspended_at IS NOT NULL),
+		        (SELECT COUNT(*) FROM shortlinks WHERE status = 'disabled')
+		 FROM users`,
+	).Scan(&o.TotalUsers, &o.AdminUsers, &o.SuspendedUsers, &o.DisabledLinks)
+	return o, err
+}
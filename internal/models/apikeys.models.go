@@ -0,0 +1,125 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	APIKeyScopeLinksRead  = "links:read"
+	APIKeyScopeLinksWrite = "links:write"
+	APIKeyScopeStatsRead  = "stats:read"
+)
+
+var APIKeyScopes = []string{APIKeyScopeLinksRead, APIKeyScopeLinksWrite, APIKeyScopeStatsRead}
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	LastUsedIP *string    `json:"lastUsedIp"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// APIKeyOwner is what the middleware needs to authenticate a key.
+type APIKeyOwner struct {
+	KeyID     int64
+	UserID    int64
+	Email     string
+	Role      string
+	Scopes    []string
+	ExpiresAt *time.Time
+	Suspended bool
+}
+
+func (o APIKeyOwner) Expired() bool {
+	return o.ExpiresAt != nil && !o.ExpiresAt.After(time.Now())
+}
+
+func (o APIKeyOwner) HasScope(scope string) bool {
+	return slices.Contains(o.Scopes, scope)
+}
+
+func CreateAPIKey(db *pgxpool.Pool, userID int64, name, prefix, keyHash string, scopes []string, expiresAt *time.Time) (APIKey, error) {
+	var k APIKey
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO api_keys (user_id, name, key_prefix, key_hash, scopes, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, name, key_prefix, scopes, expires_at, last_used_at, last_used_ip, created_at`,
+		userID, name, prefix, keyHash, scopes, utcTime(expiresAt),
+	).Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.ExpiresAt, &k.LastUsedAt, &k.LastUsedIP, &k.CreatedAt)
+	return k, err
+}
+
+func CountAPIKeysByUser(db *pgxpool.Pool, userID int64) (int, error) {
+	var n int
+	err := db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM api_keys WHERE user_id = $1`, userID,
+	).Scan(&n)
+	return n, err
+}
+
+func GetAPIKeysByUser(db *pgxpool.Pool, userID int64) ([]APIKey, error) {
+	rows, err := db.Query(context.Background(),
+		`SELECT id, name, key_prefix, scopes, expires_at, last_used_at, last_used_ip, created_at
+		 FROM api_keys WHERE user_id = $1
+		 ORDER BY created_at DESC, id DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		var k APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.ExpiresAt, &k.LastUsedAt, &k.LastUsedIP, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// DeleteAPIKey reports false when the user has no key with that id.
+func DeleteAPIKey(db *pgxpool.Pool, id, userID int64) (bool, error) {
+	tag, err := db.Exec(context.Background(),
+		`DELETE FROM api_keys WHERE id = $1 AND user_id = $2`, id, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetAPIKeyOwner looks a key up by its hash. It returns pgx.ErrNoRows for
+// unknown keys.
+func GetAPIKeyOwner(db *pgxpool.Pool, keyHash string) (APIKeyOwner, error) {
+	var o APIKeyOwner
+	err := db.QueryRow(context.Background(),
+		`SELECT k.id, k.user_id, u.email, u.role, k.scopes, k.expires_at, u.suspended_at IS NOT NULL
+		 FROM api_keys k
+		 JOIN users u ON u.id = k.user_id
+		 WHERE k.key_hash = $1`,
+		keyHash,
+	).Scan(&o.KeyID, &o.UserID, &o.Email, &o.Role, &o.Scopes, &o.ExpiresAt, &o.Suspended)
+	return o, err
+}
+
+// TouchAPIKey records a use of the key. It writes at most once a minute per
+// key so a busy script doesn't turn every request into an UPDATE.
+func TouchAPIKey(db *pgxpool.Pool, id int64, ip string) error {
+	_, err := db.Exec(context.Background(),
+		`UPDATE api_keys SET last_used_at = now(), last_used_ip = $2
+		 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute' OR last_used_ip IS DISTINCT FROM $2)`,
+		id, ip,
+	)
+	return err
+}
@@ -12,7 +12,6 @@ type UserRegister struct {
     Fullname string `json:"fullname" binding:"required"`
     Email    string `json:"email" binding:"required,email"`
     Password string `json:"password" binding:"required,min=6"`
-    Role     string `json:"role"`
 } 
 
 
@@ -21,6 +20,7 @@ type UserResponse struct {
     Fullname  string    `json:"fullname"`
     Email     string    `json:"email"`
     Role      string    `json:"role"`
+    EmailVerified bool  `json:"emailVerified"`
     Token     string    `json:"token,omitempty"`
     CreatedAt time.Time `json:"createdAt"`
     UpdatedAt time.Time `json:"updatedAt"`
@@ -34,11 +34,6 @@ type UserLogin struct {
 func RegisterUser(db *pgxpool.Pool, user UserRegister, hashedPassword string) (UserResponse, error) {
     var resp UserResponse
 
-    role := user.Role
-    if role == "" {
-        role = "user"
-    }
-
     query := `
         INSERT INTO users (fullname, email, password, role, created_at, updated_at)
         VALUES ($1, $2, $3, $4, NOW(), NOW())
@@ -51,7 +46,7 @@ func RegisterUser(db *pgxpool.Pool, user UserRegister, hashedPassword string) (U
         user.Fullname,
         user.Email,
         hashedPassword,
-        role,
+        RoleUser,
     ).Scan(
         &resp.ID,
         &resp.Fullname,
@@ -74,7 +69,7 @@ func LoginUser(db *pgxpool.Pool, email string) (*UserResponse, string, string, e
 	var hashedPassword string
 
 	query := `
-		SELECT id, fullname, email, password, role, created_at, updated_at
+		SELECT id, fullname, email, password, role, email_verified_at IS NOT NULL, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -85,6 +80,7 @@ func LoginUser(db *pgxpool.Pool, email string) (*UserResponse, string, string, e
 		&user.Email,
 		&hashedPassword,
 		&user.Role,
+		&user.EmailVerified,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -95,3 +91,14 @@ func LoginUser(db *pgxpool.Pool, email string) (*UserResponse, string, string, e
 	return &user, hashedPassword, user.Role, nil
 }
 
+
+// GetUserAccess returns the user's current role and whether the account is
+// suspended, for decisions that must not trust the role baked into a token.
+func GetUserAccess(db *pgxpool.Pool, userID int64) (string, bool, error) {
+	var role string
+	var suspended bool
+	err := db.QueryRow(context.Background(),
+		`SELECT role, suspended_at IS NOT NULL FROM users WHERE id = $1`, userID,
+	).Scan(&role, &suspended)
+	return role, suspended, err
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BreakdownItem struct {
+	Name   string `json:"name"`
+	Clicks int    `json:"clicks"`
+}
+
+type ClickBreakdowns struct {
+	Browsers         []BreakdownItem `json:"browsers"`
+	OperatingSystems []BreakdownItem `json:"os"`
+	Devices          []BreakdownItem `json:"devices"`
+	Referrers        []BreakdownItem `json:"referrers"`
+	Countries        []BreakdownItem `json:"countries"`
+	Regions          []BreakdownItem `json:"regions"`
+	Cities           []BreakdownItem `json:"cities"`
+}
+
+// GetClickBreakdowns groups the shortlink_clicks rows matching where by
+// browser, OS, device class, referring domain and geography. where is a fixed SQL
+// condition written by the caller; values go through args.
+func GetClickBreakdowns(db *pgxpool.Pool, where string, args ...any) (ClickBreakdowns, error) {
+	var b ClickBreakdowns
+	var err error
+
+	if b.Browsers, err = clickBreakdown(db, `COALESCE(browser, 'unknown')`, where, args); err != nil {
+		return b, err
+	}
+	if b.OperatingSystems, err = clickBreakdown(db, `COALESCE(os, 'unknown')`, where, args); err != nil {
+		return b, err
+	}
+	if b.Devices, err = clickBreakdown(db, `COALESCE(device, 'unknown')`, where, args); err != nil {
+		return b, err
+	}
+	if b.Referrers, err = clickBreakdown(db, `COALESCE(referrer_domain, 'direct')`, where, args); err != nil {
+		return b, err
+	}
+	if b.Countries, err = clickBreakdown(db, `COALESCE(country_code, 'unknown')`, where, args); err != nil {
+		return b, err
+	}
+	if b.Regions, err = clickBreakdown(db, `COALESCE(region_code, 'unknown')`, where, args); err != nil {
+		return b, err
+	}
+	if b.Cities, err = clickBreakdown(db, `COALESCE(city, 'unknown')`, where, args); err != nil {
+		return b, err
+	}
+
+	return b, nil
+}
+
+func clickBreakdown(db *pgxpool.Pool, dimension, where string, args []any) ([]BreakdownItem, error) {
+	rows, err := db.Query(context.Background(),
+		`SELECT `+dimension+` AS name, COUNT(*) AS clicks
+		 FROM shortlink_clicks
+		 WHERE `+where+`
+		 GROUP BY 1
+		 ORDER BY clicks DESC
+		 LIMIT 10`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []BreakdownItem{}
+	for rows.Next() {
+		var item BreakdownItem
+		if err := rows.Scan(&item.Name, &item.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
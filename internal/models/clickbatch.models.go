@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var clickColumns = []string{
+	"shortlink_id", "ip_address", "user_agent", "referrer", "referrer_domain", "browser", "os", "device",
+	"country_code", "region_code", "city", "is_bot", "clicked_at",
+}
+
+// SaveClickBatch writes clicks with COPY and adds the per-link redirect
+// counts in the same transaction, so a batch is either stored whole or not
+// at all. The clicks go through a temporary table so that clicks of links
+// deleted while they were queued are skipped instead of failing the batch.
+// It returns the redirect_count of every link in redirects after the update.
+func SaveClickBatch(db *pgxpool.Pool, clicks []ShortlinkClick, redirects map[int]int) (map[int]int, error) {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if len(clicks) > 0 {
+		_, err = tx.Exec(ctx, `CREATE TEMP TABLE click_batch (LIKE shortlink_clicks) ON COMMIT DROP`)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"click_batch"}, clickColumns,
+			pgx.CopyFromSlice(len(clicks), func(i int) ([]any, error) {
+				c := clicks[i]
+				return []any{
+					c.ShortlinkID, cleanText(c.IP), cleanText(c.UserAgent), nullIfEmpty(c.Referrer), nullIfEmpty(c.ReferrerDomain),
+					nullIfEmpty(c.Browser), nullIfEmpty(c.OS), nullIfEmpty(c.Device),
+					nullIfEmpty(c.CountryCode), nullIfEmpty(c.RegionCode), nullIfEmpty(c.City), c.IsBot, c.CreatedAt,
+				}, nil
+			}),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		cols := strings.Join(clickColumns, ", ")
+		_, err = tx.Exec(ctx,
+			`INSERT INTO shortlink_clicks (`+cols+`)
+			 SELECT `+cols+` FROM click_batch b
+			 WHERE EXISTS (SELECT 1 FROM shortlinks s WHERE s.id = b.shortlink_id)`,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	totals, err := addRedirectCounts(ctx, tx, redirects)
+	if err != nil {
+		return nil, err
+	}
+
+	return totals, tx.Commit(ctx)
+}
+
+// AddRedirectCounts adds to redirect_count on its own, for clicks whose row
+// could not be stored but whose redirect did happen.
+func AddRedirectCounts(db *pgxpool.Pool, redirects map[int]int) (map[int]int, error) {
+	return addRedirectCounts(context.Background(), db, redirects)
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// addRedirectCounts returns the new redirect_count of each link, leaving out
+// links that no longer exist.
+func addRedirectCounts(ctx context.Context, db querier, redirects map[int]int) (map[int]int, error) {
+	totals := make(map[int]int, len(redirects))
+	if len(redirects) == 0 {
+		return totals, nil
+	}
+
+	ids := make([]int32, 0, len(redirects))
+	counts := make([]int32, 0, len(redirects))
+	for id, n := range redirects {
+		ids = append(ids, int32(id))
+		counts = append(counts, int32(n))
+	}
+
+	rows, err := db.Query(ctx,
+		`UPDATE shortlinks s
+		 SET redirect_count = s.redirect_count + v.n, updated_at = now()
+		 FROM unnest($1::int[], $2::int[]) AS v(id, n)
+		 WHERE s.id = v.id
+		 RETURNING s.id, s.redirect_count`,
+		ids, counts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, total int
+		if err := rows.Scan(&id, &total); err != nil {
+			return nil, err
+		}
+		totals[id] = total
+	}
+	return totals, rows.Err()
+}
+
+// cleanText makes client-supplied text storable: Postgres rejects invalid
+// UTF-8 and NUL bytes in text columns.
+func cleanText(s string) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(s, "\uFFFD"), "\x00", "")
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return cleanText(s)
+}
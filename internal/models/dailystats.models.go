@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// rollupLockID keys the advisory lock that keeps concurrent aggregators and
+// backfills from writing the same days.
+const rollupLockID = 7_150_015
+
+// rollupWatermarkSQL yields the first day not yet rolled up, -infinity when
+// nothing is.
+const rollupWatermarkSQL = `SELECT COALESCE((SELECT rolled_up_to FROM shortlink_rollup_state), '-infinity'::date) AS day`
+
+// GetRollupWatermark returns the first day whose clicks are not yet in
+// shortlink_daily_stats, or nil when nothing has been rolled up.
+func GetRollupWatermark(db *pgxpool.Pool) (*time.Time, error) {
+	var day *time.Time
+	err := db.QueryRow(context.Background(),
+		`SELECT (SELECT rolled_up_to FROM shortlink_rollup_state)`,
+	).Scan(&day)
+	return day, err
+}
+
+// RawClicksRetiredError means a range needs raw clicks from before From,
+// which the retention policy has dropped or archived, for something rollups
+// can't stand in for: hourly buckets, days in another time zone or single
+// clicks.
+type RawClicksRetiredError struct {
+	From time.Time
+}
+
+func (e *RawClicksRetiredError) Error() string {
+	return "raw clicks before " + e.From.Format("2006-01-02") + " are no longer kept"
+}
+
+// CheckRawClicksKept returns a *RawClicksRetiredError when from is before the
+// first day whose raw clicks are still kept.
+func CheckRawClicksKept(db *pgxpool.Pool, from time.Time) error {
+	var rawFrom *time.Time
+	err := db.QueryRow(context.Background(),
+		`SELECT (SELECT raw_from FROM shortlink_rollup_state)`,
+	).Scan(&rawFrom)
+	if err != nil {
+		return err
+	}
+	if rawFrom != nil && from.Before(*rawFrom) {
+		return &RawClicksRetiredError{From: *rawFrom}
+	}
+	return nil
+}
+
+// GetFirstClickDay returns the day of the oldest click, or nil when there
+// are none.
+func GetFirstClickDay(db *pgxpool.Pool) (*time.Time, error) {
+	var day *time.Time
+	err := db.QueryRow(context.Background(),
+		`SELECT MIN(clicked_at)::date FROM shortlink_clicks`,
+	).Scan(&day)
+	return day, err
+}
+
+// RollupDays recomputes shortlink_daily_stats for the days in [from, to)
+// from the raw clicks. clicks and uniques (distinct IP and user agent) only
+// count humans; bots are counted separately.
+func RollupDays(db *pgxpool.Pool, from, to time.Time) (int64, error) {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, rollupLockID); err != nil {
+		return 0, err
+	}
+
+	// Days whose raw partitions were retired only survive as rollups.
+	_, err = tx.Exec(ctx,
+		`DELETE FROM shortlink_daily_stats
+		 WHERE day >= $1::date AND day < $2::date
+		 AND day >= COALESCE((SELECT raw_from FROM shortlink_rollup_state), '-infinity'::date)`,
+		from, to,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO shortlink_daily_stats (shortlink_id, day, clicks, uniques, bots, updated_at)
+		 SELECT shortlink_id,
+		        clicked_at::date,
+		        COUNT(*) FILTER (WHERE NOT is_bot),
+		        COUNT(DISTINCT (ip_address, user_agent)) FILTER (WHERE NOT is_bot),
+		        COUNT(*) FILTER (WHERE is_bot),
+		        now()
+		 FROM shortlink_clicks
+		 WHERE clicked_at >= $1::date AND clicked_at < $2::date
+		 GROUP BY 1, 2
+		 ON CONFLICT (shortlink_id, day) DO NOTHING`,
+		from, to,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), tx.Commit(ctx)
+}
+
+// AdvanceRollupWatermark moves the watermark to to, but only when the days
+// just rolled up starting at from leave no gap behind the current watermark
+// (or, before the first rollup, behind the oldest click).
+func AdvanceRollupWatermark(db *pgxpool.Pool, from, to time.Time) (bool, error) {
+	tag, err := db.Exec(context.Background(),
+		`UPDATE shortlink_rollup_state
+		 SET rolled_up_to = $2::date, updated_at = now()
+		 WHERE COALESCE(rolled_up_to, (SELECT MIN(clicked_at)::date FROM shortlink_clicks), $1::date) >= $1::date
+		 AND (rolled_up_to IS NULL OR rolled_up_to < $2::date)`,
+		from, to,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// canUseRollups reports whether a range can be answered from daily rollups:
+// they are UTC days, so the range must start and end at UTC midnight and the
+// buckets must be whole days.
+func canUseRollups(from, to time.Time, loc *time.Location, granularity string) bool {
+	if granularity == "hour" || loc.String() != "UTC" {
+		return false
+	}
+	return from.UTC().Equal(from.UTC().Truncate(24*time.Hour)) && to.UTC().Equal(to.UTC().Truncate(24*time.Hour))
+}
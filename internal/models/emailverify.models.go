@@ -0,0 +1,41 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func IsEmailVerified(db *pgxpool.Pool, userID int64) (bool, error) {
+	var verified bool
+	err := db.QueryRow(context.Background(),
+		`SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1`, userID,
+	).Scan(&verified)
+	return verified, err
+}
+
+// GetVerificationUser returns the user a verification mail is addressed to.
+func GetVerificationUser(db *pgxpool.Pool, userID int64) (UserResponse, error) {
+	var u UserResponse
+	err := db.QueryRow(context.Background(),
+		`SELECT id, fullname, email, role, email_verified_at IS NOT NULL, created_at, updated_at
+		 FROM users WHERE id = $1`, userID,
+	).Scan(&u.ID, &u.Fullname, &u.Email, &u.Role, &u.EmailVerified, &u.CreatedAt, &u.UpdatedAt)
+	return u, err
+}
+
+// MarkEmailVerified verifies the user's address as long as it is still the
+// one the token was issued for. It returns pgx.ErrNoRows otherwise, and
+// whether the address had been verified before.
+func MarkEmailVerified(db *pgxpool.Pool, userID int64, email string) (bool, error) {
+	var already bool
+	err := db.QueryRow(context.Background(),
+		`UPDATE users u
+		 SET email_verified_at = COALESCE(u.email_verified_at, now())
+		 FROM users old
+		 WHERE u.id = $1 AND u.email = $2 AND old.id = u.id
+		 RETURNING old.email_verified_at IS NOT NULL`,
+		userID, email,
+	).Scan(&already)
+	return already, err
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExportedClick struct {
+	ID             int       `json:"id"`
+	ShortCode      string    `json:"shortCode"`
+	IP             string    `json:"ip"`
+	UserAgent      string    `json:"userAgent"`
+	ReferrerDomain string    `json:"referrerDomain"`
+	Browser        string    `json:"browser"`
+	OS             string    `json:"os"`
+	Device         string    `json:"device"`
+	CountryCode    string    `json:"countryCode"`
+	RegionCode     string    `json:"regionCode"`
+	City           string    `json:"city"`
+	IsBot          bool      `json:"isBot"`
+	ClickedAt      time.Time `json:"clickedAt"`
+}
+
+// StreamShortlinksByUser calls fn for every shortlink of the user, oldest
+// first. Rows are read from the pgx cursor one at a time, never buffered.
+func StreamShortlinksByUser(db *pgxpool.Pool, userID int64, fn func(Shortlink) error) error {
+	rows, err := db.Query(context.Background(),
+		`SELECT id, user_id, original_url, short_code, redirect_count, status, expires_at, max_clicks, password_hash IS NOT NULL, created_at, updated_at
+		 FROM shortlinks
+		 WHERE user_id=$1
+		 ORDER BY id`, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sl Shortlink
+		if err := rows.Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.Status, &sl.ExpiresAt, &sl.MaxClicks, &sl.Protected, &sl.CreatedAt, &sl.UpdatedAt); err != nil {
+			return err
+		}
+		if err := fn(sl); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// StreamClicksByUser calls fn for every click on the user's shortlinks
+// within [from, to). A nil bound leaves that side of the range open.
+func StreamClicksByUser(db *pgxpool.Pool, userID int64, from, to *time.Time, fn func(ExportedClick) error) error {
+	rows, err := db.Query(context.Background(),
+		`SELECT c.id, s.short_code, COALESCE(c.ip_address, ''), COALESCE(c.user_agent, ''),
+		        COALESCE(c.referrer_domain, ''), COALESCE(c.browser, ''), COALESCE(c.os, ''), COALESCE(c.device, ''),
+		        COALESCE(c.country_code, ''), COALESCE(c.region_code, ''), COALESCE(c.city, ''), c.is_bot, c.clicked_at
+		 FROM shortlink_clicks c
+		 JOIN shortlinks s ON s.id = c.shortlink_id
+		 WHERE s.user_id=$1
+		 AND ($2::timestamp IS NULL OR c.clicked_at >= $2)
+		 AND ($3::timestamp IS NULL OR c.clicked_at < $3)
+		 ORDER BY c.clicked_at`, userID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var click ExportedClick
+		if err := rows.Scan(&click.ID, &click.ShortCode, &click.IP, &click.UserAgent, &click.ReferrerDomain, &click.Browser, &click.OS, &click.Device, &click.CountryCode, &click.RegionCode, &click.City, &click.IsBot, &click.ClickedAt); err != nil {
+			return err
+		}
+		if err := fn(click); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"context"
+	"koda-shortlink/internal/utils"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+type TimeBucket struct {
+	Bucket       time.Time `json:"bucket"`
+	Clicks       int       `json:"clicks"`
+	UniqueVisits *int      `json:"uniqueVisits,omitempty"`
+}
+
+type UserAgentCount struct {
+	UserAgent string `json:"userAgent"`
+	Clicks    int    `json:"clicks"`
+}
+
+type LinkStats struct {
+	ShortCode   string    `json:"shortCode"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Granularity string    `json:"granularity"`
+	TotalClicks int       `json:"totalClicks"`
+	// UniqueClicks sums the distinct IP and user agent pairs of each day,
+	// humans only.
+	UniqueClicks  int              `json:"uniqueClicks"`
+	UniqueVisits  int              `json:"uniqueVisits"`
+	Series        []TimeBucket     `json:"series"`
+	TopUserAgents []UserAgentCount `json:"topUserAgents"`
+	Breakdowns    ClickBreakdowns  `json:"breakdowns"`
+}
+
+// GetLinkStats aggregates the clicks of one link within [from, to). Closed
+// days come from shortlink_daily_stats when the range allows it, the rest from
+// the raw clicks. granularity is passed to date_trunc and must be hour, day
+// or week. Bot clicks are skipped unless includeBots is set.
+func GetLinkStats(db *pgxpool.Pool, sl Shortlink, from, to time.Time, granularity string, includeBots bool) (LinkStats, error) {
+	ctx := context.Background()
+	stats := LinkStats{
+		ShortCode:     sl.ShortCode,
+		From:          from,
+		To:            to,
+		Granularity:   granularity,
+		Series:        []TimeBucket{},
+		TopUserAgents: []UserAgentCount{},
+	}
+
+	useRollups := canUseRollups(from, to, time.UTC, granularity)
+	if !useRollups {
+		if err := CheckRawClicksKept(db, from); err != nil {
+			return stats, err
+		}
+	}
+
+	rows, err := db.Query(ctx,
+		`WITH wm AS (`+rollupWatermarkSQL+`),
+		 rolled AS (
+		     SELECT d.day::timestamp AS at, d.clicks + CASE WHEN $5 THEN d.bots ELSE 0 END AS n, d.uniques
+		     FROM shortlink_daily_stats d, wm
+		     WHERE $6 AND d.shortlink_id=$1
+		     AND d.day >= $2::timestamp::date AND d.day < LEAST($3::timestamp::date, wm.day)
+		 ),
+		 raw AS (
+		     SELECT c.clicked_at AS at, c.ip_address, c.user_agent, c.is_bot
+		     FROM shortlink_clicks c, wm
+		     WHERE c.shortlink_id=$1 AND c.clicked_at >= $2::timestamp AND c.clicked_at < $3::timestamp
+		     AND ($5 OR NOT c.is_bot)
+		     AND (NOT $6 OR c.clicked_at >= wm.day)
+		 ),
+		 events AS (
+		     SELECT at, n FROM rolled
+		     UNION ALL
+		     SELECT at, 1 FROM raw
+		 ),
+		 totals AS (
+		     SELECT (SELECT COALESCE(SUM(n), 0) FROM events) AS clicks,
+		            (SELECT COALESCE(SUM(uniques), 0) FROM rolled)
+		          + (SELECT COUNT(DISTINCT (ip_address, user_agent, at::date)) FROM raw WHERE NOT is_bot) AS uniques
+		 ),
+		 series AS (
+		     SELECT b.bucket, COALESCE(SUM(e.n), 0) AS clicks
+		     FROM generate_series(date_trunc($4, $2::timestamp), $3::timestamp - interval '1 microsecond', ('1 ' || $4)::interval) AS b(bucket)
+		     LEFT JOIN events e ON date_trunc($4, e.at) = b.bucket
+		     GROUP BY b.bucket
+		 )
+		 SELECT s.bucket, s.clicks, t.clicks, t.uniques
+		 FROM series s
+		 CROSS JOIN totals t
+		 ORDER BY s.bucket`,
+		sl.ID, from, to, granularity, includeBots, useRollups,
+	)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b TimeBucket
+		if err := rows.Scan(&b.Bucket, &b.Clicks, &stats.TotalClicks, &stats.UniqueClicks); err != nil {
+			return stats, err
+		}
+		stats.Series = append(stats.Series, b)
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	keysBetween := func(from, to time.Time) []string { return utils.LinkUniqueKeysBetween(sl.ID, from, to) }
+	uniques, err := utils.CountUniqueVisits(ctx, keysBetween(from, to)...)
+	if err != nil {
+		return stats, err
+	}
+	stats.UniqueVisits = int(uniques)
+
+	if err := fillSeriesUniques(ctx, stats.Series, granularity, keysBetween); err != nil {
+		return stats, err
+	}
+
+	rows, err = db.Query(ctx,
+		`SELECT COALESCE(user_agent, ''), COUNT(*) AS clicks
+		 FROM shortlink_clicks
+		 WHERE shortlink_id=$1 AND clicked_at >= $2 AND clicked_at < $3 AND ($4 OR NOT is_bot)
+		 GROUP BY 1
+		 ORDER BY clicks DESC
+		 LIMIT 10`,
+		sl.ID, from, to, includeBots,
+	)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ua UserAgentCount
+		if err := rows.Scan(&ua.UserAgent, &ua.Clicks); err != nil {
+			return stats, err
+		}
+		stats.TopUserAgents = append(stats.TopUserAgents, ua)
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	stats.Breakdowns, err = GetClickBreakdowns(db,
+		`shortlink_id=$1 AND clicked_at >= $2 AND clicked_at < $3 AND ($4 OR NOT is_bot)`, sl.ID, from, to, includeBots)
+	return stats, err
+}
+
+// fillSeriesUniques reads the HyperLogLog estimates of each bucket. They
+// are kept per UTC day, so only day and week buckets get one.
+func fillSeriesUniques(ctx context.Context, series []TimeBucket, granularity string, keysBetween func(from, to time.Time) []string) error {
+	var unit time.Duration
+	switch granularity {
+	case "day":
+		unit = 24 * time.Hour
+	case "week":
+		unit = 7 * 24 * time.Hour
+	default:
+		return nil
+	}
+
+	pipe := utils.RedisClient.Pipeline()
+	cmds := make([]*redis.IntCmd, len(series))
+	for i, b := range series {
+		if keys := keysBetween(b.Bucket, b.Bucket.Add(unit)); len(keys) > 0 {
+			cmds[i] = pipe.PFCount(ctx, keys...)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return err
+	}
+
+	for i, cmd := range cmds {
+		n := 0
+		if cmd != nil {
+			n = int(cmd.Val())
+		}
+		series[i].UniqueVisits = &n
+	}
+	return nil
+}
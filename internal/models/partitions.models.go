@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var clickPartitionName = regexp.MustCompile(`^shortlink_clicks_p(\d{6})$`)
+
+// ClickPartition is one monthly partition of shortlink_clicks, holding
+// [Month, Month+1 month).
+type ClickPartition struct {
+	Name  string
+	Month time.Time
+}
+
+func (p ClickPartition) End() time.Time {
+	return p.Month.AddDate(0, 1, 0)
+}
+
+// EnsureClickPartitions creates the partitions for the month of from and the
+// months following it, skipping those that exist.
+func EnsureClickPartitions(db *pgxpool.Pool, from time.Time, months int) error {
+	_, err := db.Exec(context.Background(),
+		`SELECT create_shortlink_clicks_partition(m::date)
+		 FROM generate_series(date_trunc('month', $1::timestamp), date_trunc('month', $1::timestamp) + make_interval(months => $2), interval '1 month') AS m`,
+		from, months,
+	)
+	return err
+}
+
+// ListClickPartitions returns the monthly partitions still attached, oldest
+// first.
+func ListClickPartitions(db *pgxpool.Pool) ([]ClickPartition, error) {
+	rows, err := db.Query(context.Background(),
+		`SELECT c.relname
+		 FROM pg_inherits i
+		 JOIN pg_class c ON c.oid = i.inhrelid
+		 WHERE i.inhparent = 'shortlink_clicks'::regclass
+		 ORDER BY c.relname`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []ClickPartition
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		m := clickPartitionName.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		month, err := time.Parse("200601", m[1])
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, ClickPartition{Name: name, Month: month})
+	}
+
+	return partitions, rows.Err()
+}
+
+// RetireClickPartition detaches a partition and either drops it or moves it
+// to the archive schema. raw_from is advanced so later rollups leave the
+// days of the partition alone.
+func RetireClickPartition(db *pgxpool.Pool, p ClickPartition, archive bool) error {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, rollupLockID); err != nil {
+		return err
+	}
+
+	name := pgx.Identifier{p.Name}.Sanitize()
+	if _, err := tx.Exec(ctx, `ALTER TABLE shortlink_clicks DETACH PARTITION `+name); err != nil {
+		return err
+	}
+
+	if archive {
+		_, err = tx.Exec(ctx, `ALTER TABLE `+name+` SET SCHEMA archive`)
+	} else {
+		_, err = tx.Exec(ctx, `DROP TABLE `+name)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE shortlink_rollup_state
+		 SET raw_from = GREATEST(COALESCE(raw_from, '-infinity'::date), $1::date), updated_at = now()`,
+		p.End(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StartPasswordReset stores the hashed reset token and OTP of the user with
+// email, replacing any earlier request. Suspended users can't reset. It
+// returns pgx.ErrNoRows when there is nobody to send the reset to.
+func StartPasswordReset(db *pgxpool.Pool, email, tokenHash, otpHash string, ttl time.Duration) (UserResponse, error) {
+	var user UserResponse
+	err := db.QueryRow(context.Background(),
+		`UPDATE users
+		 SET reset_token = $2, reset_otp = $3, reset_expires = now() + $4::float8 * interval '1 second'
+		 WHERE email = $1 AND suspended_at IS NULL
+		 RETURNING id, fullname, email`,
+		email, tokenHash, otpHash, ttl.Seconds(),
+	).Scan(&user.ID, &user.Fullname, &user.Email)
+	return user, err
+}
+
+// FindPasswordResetByToken returns the user with an unexpired reset whose
+// token hashes to tokenHash.
+func FindPasswordResetByToken(db *pgxpool.Pool, tokenHash string) (int64, error) {
+	var id int64
+	err := db.QueryRow(context.Background(),
+		`SELECT id FROM users WHERE reset_token = $1 AND reset_expires > now()`,
+		tokenHash,
+	).Scan(&id)
+	return id, err
+}
+
+// FindPasswordResetByEmail returns the user with email and an unexpired
+// reset, along with whether otpHash matches its OTP.
+func FindPasswordResetByEmail(db *pgxpool.Pool, email, otpHash string) (int64, bool, error) {
+	var id int64
+	var match bool
+	err := db.QueryRow(context.Background(),
+		`SELECT id, reset_otp = $2 FROM users
+		 WHERE email = $1 AND reset_otp IS NOT NULL AND reset_expires > now()`,
+		email, otpHash,
+	).Scan(&id, &match)
+	return id, match, err
+}
+
+func ClearPasswordReset(db *pgxpool.Pool, userID int64) error {
+	_, err := db.Exec(context.Background(),
+		`UPDATE users SET reset_token = NULL, reset_otp = NULL, reset_expires = NULL WHERE id = $1`,
+		userID,
+	)
+	return err
+}
+
+// CompletePasswordReset sets the new password, uses up the reset and signs
+// the user out everywhere. The reset is checked and used up in the same
+// statement, by tokenHash or else otpHash, so a token can't be redeemed twice
+// by concurrent requests; pgx.ErrNoRows means it no longer matches.
+func CompletePasswordReset(db *pgxpool.Pool, userID int64, tokenHash, otpHash, hashedPassword string) error {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE users
+		 SET password = $2, reset_token = NULL, reset_otp = NULL, reset_expires = NULL, updated_at = now()
+		 WHERE id = $1 AND reset_expires > now()
+		 AND (reset_token = NULLIF($3, '') OR reset_otp = NULLIF($4, ''))`,
+		userID, hashedPassword, tokenHash, otpHash,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -9,20 +9,21 @@ import (
 )
 
 type UserProfileResponse struct {
-	Fullname string  `json:"fullname"`
-	Email    string  `json:"email"`
-	Image    *string `json:"image"`
+	Fullname      string  `json:"fullname"`
+	Email         string  `json:"email"`
+	EmailVerified bool    `json:"emailVerified"`
+	Image         *string `json:"image"`
 }
 
 func GetUserProfile(db *pgxpool.Pool, userID int) (UserProfileResponse, error) {
 	var profile UserProfileResponse
 
 	err := db.QueryRow(context.Background(),
-		`SELECT u.fullname, u.email, p.image
+		`SELECT u.fullname, u.email, u.email_verified_at IS NOT NULL, p.image
 	 FROM users u
 	 LEFT JOIN profile p ON u.id = p.user_id
 	 WHERE u.id=$1`, userID,
-	).Scan(&profile.Fullname, &profile.Email, &profile.Image)
+	).Scan(&profile.Fullname, &profile.Email, &profile.EmailVerified, &profile.Image)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -35,16 +36,22 @@ func GetUserProfile(db *pgxpool.Pool, userID int) (UserProfileResponse, error) {
 
 }
 
-func UpdateUserProfile(db *pgxpool.Pool, userID int, fullname, email, image *string) error {
-	_, err := db.Exec(context.Background(),
+// UpdateUserProfile reports whether the email changed, in which case the
+// address is unverified again.
+func UpdateUserProfile(db *pgxpool.Pool, userID int, fullname, email, image *string) (bool, error) {
+	var emailChanged bool
+	err := db.QueryRow(context.Background(),
 		`UPDATE users u
 		 SET fullname = COALESCE($1, u.fullname),
-		     email = COALESCE($2, u.email)
-		 WHERE u.id = $3`,
+		     email = COALESCE($2, u.email),
+		     email_verified_at = CASE WHEN COALESCE($2, u.email) = u.email THEN u.email_verified_at END
+		 FROM users old
+		 WHERE u.id = $3 AND old.id = u.id
+		 RETURNING u.email <> old.email`,
 		fullname, email, userID,
-	)
+	).Scan(&emailChanged)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if image != nil {
@@ -55,10 +62,10 @@ func UpdateUserProfile(db *pgxpool.Pool, userID int, fullname, email, image *str
 			userID, image,
 		)
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
 
-	return nil
+	return emailChanged, nil
 
 }
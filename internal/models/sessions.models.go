@@ -2,37 +2,216 @@ package models
 
 import (
     "context"
+    "errors"
     "time"
 
+    "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrRefreshTokenReused means a refresh token that had already been rotated
+// was presented again. Its whole family has been revoked by then.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
 type Session struct {
-    ID           int
-    UserID       int
-    RefreshToken string
-    ExpiresAt    time.Time
+    ID        int
+    UserID    int
+    FamilyID  string
+    ExpiresAt time.Time
 }
 
-func CreateSession(db *pgxpool.Pool, userID int, token, userAgent, ip string, expiresAt time.Time) error {
+// CreateSession starts a new token family with its first refresh token and
+// returns the family ID.
+func CreateSession(db *pgxpool.Pool, userID int, tokenHash, userAgent, ip string, expiresAt time.Time) (string, error) {
     ctx := context.Background()
-    _, err := db.Exec(ctx,
-        "INSERT INTO sessions (user_id, refresh_token, user_agent, ip_address, expires_at) VALUES ($1,$2,$3,$4,$5)",
-        userID, token, userAgent, ip, expiresAt,
-    )
-    return err
+    var familyID string
+    err := db.QueryRow(ctx,
+        "INSERT INTO sessions (user_id, refresh_token_hash, user_agent, ip_address, expires_at) VALUES ($1,$2,$3,$4,$5) RETURNING family_id::text",
+        userID, tokenHash, userAgent, ip, expiresAt,
+    ).Scan(&familyID)
+    return familyID, err
 }
 
-func GetSessionByToken(db *pgxpool.Pool, token string) (Session, error) {
+// RotateSession swaps the refresh token oldHash for newHash within the same
+// family. It returns pgx.ErrNoRows for unknown or expired tokens, and
+// ErrRefreshTokenReused after revoking the family when oldHash had already
+// been rotated.
+func RotateSession(db *pgxpool.Pool, oldHash, newHash, userAgent, ip string, expiresAt time.Time) (Session, error) {
     ctx := context.Background()
+    tx, err := db.Begin(ctx)
+    if err != nil {
+        return Session{}, err
+    }
+    defer tx.Rollback(ctx)
+
     var s Session
-    err := db.QueryRow(ctx, "SELECT id, user_id, refresh_token, expires_at FROM sessions WHERE refresh_token=$1", token).
-        Scan(&s.ID, &s.UserID, &s.RefreshToken, &s.ExpiresAt)
-    return s, err
+    var signedInAt time.Time
+    var rotated bool
+    err = tx.QueryRow(ctx,
+        `SELECT id, user_id, family_id::text, expires_at, signed_in_at, rotated_at IS NOT NULL
+         FROM sessions WHERE refresh_token_hash = $1 FOR UPDATE`,
+        oldHash,
+    ).Scan(&s.ID, &s.UserID, &s.FamilyID, &s.ExpiresAt, &signedInAt, &rotated)
+    if err != nil {
+        return Session{}, err
+    }
+
+    switch err := checkRotation(rotated, s.ExpiresAt, time.Now()); {
+    case errors.Is(err, ErrRefreshTokenReused):
+        if _, err := tx.Exec(ctx, "DELETE FROM sessions WHERE family_id = $1", s.FamilyID); err != nil {
+            return Session{}, err
+        }
+        if err := tx.Commit(ctx); err != nil {
+            return Session{}, err
+        }
+        return s, ErrRefreshTokenReused
+    case err != nil:
+        return Session{}, err
+    }
+
+    if _, err := tx.Exec(ctx, "UPDATE sessions SET rotated_at = now() WHERE id = $1", s.ID); err != nil {
+        return Session{}, err
+    }
+
+    err = tx.QueryRow(ctx,
+        `INSERT INTO sessions (user_id, family_id, refresh_token_hash, user_agent, ip_address, expires_at, signed_in_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7)
+         RETURNING id`,
+        s.UserID, s.FamilyID, newHash, userAgent, ip, expiresAt, signedInAt,
+    ).Scan(&s.ID)
+    if err != nil {
+        return Session{}, err
+    }
+    s.ExpiresAt = expiresAt
+
+    return s, tx.Commit(ctx)
+}
+
+// checkRotation decides whether a refresh token may be rotated. A token that
+// was rotated before is being reused, even after it expired, and ends its
+// whole family; an expired one is treated as unknown.
+func checkRotation(rotated bool, expiresAt, now time.Time) error {
+    if rotated {
+        return ErrRefreshTokenReused
+    }
+    if expiresAt.Before(now) {
+        return pgx.ErrNoRows
+    }
+    return nil
 }
 
-func DeleteSession(db *pgxpool.Pool, token string) error {
+// DeleteSession ends the session the refresh token belongs to, including
+// the tokens rotated before it, and returns its ID, or "" when there was no
+// such session.
+func DeleteSession(db *pgxpool.Pool, tokenHash string) (string, error) {
     ctx := context.Background()
-    _, err := db.Exec(ctx, "DELETE FROM sessions WHERE refresh_token=$1", token)
+    var familyID string
+    err := db.QueryRow(ctx,
+        `WITH deleted AS (
+             DELETE FROM sessions WHERE family_id = (SELECT family_id FROM sessions WHERE refresh_token_hash=$1)
+             RETURNING family_id
+         )
+         SELECT family_id::text FROM deleted LIMIT 1`,
+        tokenHash,
+    ).Scan(&familyID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", nil
+    }
+    return familyID, err
+}
+
+// DeleteUserSessions ends every session of the user.
+func DeleteUserSessions(db *pgxpool.Pool, userID int64) error {
+    _, err := db.Exec(context.Background(), "DELETE FROM sessions WHERE user_id = $1", userID)
     return err
 }
+
+// ActiveSession is a signed-in device: the current row of a token family.
+// Browser, OS, Device and Current are filled in by the handler.
+type ActiveSession struct {
+    ID         string    `json:"id"`
+    UserAgent  *string   `json:"userAgent"`
+    IPAddress  *string   `json:"ipAddress"`
+    Browser    string    `json:"browser"`
+    OS         string    `json:"os"`
+    Device     string    `json:"device"`
+    Current    bool      `json:"current"`
+    SignedInAt time.Time `json:"signedInAt"`
+    LastUsedAt time.Time `json:"lastUsedAt"`
+    ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// ListActiveSessions returns the user's unexpired sessions, most recently
+// used first.
+func ListActiveSessions(db *pgxpool.Pool, userID int64) ([]ActiveSession, error) {
+    rows, err := db.Query(context.Background(),
+        `SELECT family_id::text, user_agent, ip_address, signed_in_at, COALESCE(created_at, signed_in_at), expires_at
+         FROM sessions
+         WHERE user_id = $1 AND rotated_at IS NULL AND expires_at > now()
+         ORDER BY created_at DESC`,
+        userID,
+    )
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    sessions := []ActiveSession{}
+    for rows.Next() {
+        var s ActiveSession
+        if err := rows.Scan(&s.ID, &s.UserAgent, &s.IPAddress, &s.SignedInAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+            return nil, err
+        }
+        sessions = append(sessions, s)
+    }
+    return sessions, rows.Err()
+}
+
+// RevokeSession ends one of the user's sessions. It reports false when the
+// user has no session with that ID.
+func RevokeSession(db *pgxpool.Pool, userID int64, familyID string) (bool, error) {
+    tag, err := db.Exec(context.Background(),
+        "DELETE FROM sessions WHERE user_id = $1 AND family_id::text = $2",
+        userID, familyID,
+    )
+    if err != nil {
+        return false, err
+    }
+    return tag.RowsAffected() > 0, nil
+}
+
+// RevokeOtherSessions ends every session of the user except keepFamilyID and
+// returns the IDs of the sessions ended.
+func RevokeOtherSessions(db *pgxpool.Pool, userID int64, keepFamilyID string) ([]string, error) {
+    rows, err := db.Query(context.Background(),
+        `WITH deleted AS (
+             DELETE FROM sessions WHERE user_id = $1 AND family_id::text <> $2 RETURNING family_id
+         )
+         SELECT DISTINCT family_id::text FROM deleted`,
+        userID, keepFamilyID,
+    )
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    ids := []string{}
+    for rows.Next() {
+        var id string
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+// PurgeExpiredSessions deletes refresh tokens past their expiry. Their JWTs
+// have expired too, so rotated ones are no longer needed to detect reuse.
+func PurgeExpiredSessions(db *pgxpool.Pool) (int64, error) {
+    tag, err := db.Exec(context.Background(), "DELETE FROM sessions WHERE expires_at < now()")
+    if err != nil {
+        return 0, err
+    }
+    return tag.RowsAffected(), nil
+}
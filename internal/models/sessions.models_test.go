@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestCheckRotation(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		rotated   bool
+		expiresAt time.Time
+		want      error
+	}{
+		{"rotate", false, now.Add(time.Hour), nil},
+		{"reused", true, now.Add(time.Hour), ErrRefreshTokenReused},
+		{"reused after expiry", true, now.Add(-time.Hour), ErrRefreshTokenReused},
+		{"expired", false, now.Add(-time.Second), pgx.ErrNoRows},
+		{"expires now", false, now, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRotation(tt.rotated, tt.expiresAt, now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("checkRotation() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
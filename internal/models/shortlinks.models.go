@@ -2,12 +2,24 @@ package models
 
 import (
 	"context"
+	"errors"
 	"koda-shortlink/internal/utils"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// queryRower is satisfied by both *pgxpool.Pool and pgx.Tx.
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const MaxShortCodeAttempts = 5
+
+var ErrShortCodeExhausted = errors.New("could not generate a unique short code")
+
 type Shortlink struct {
 	ID            int     `json:"id"`
 	UserID        *int64  `json:"userId"`
@@ -15,35 +27,222 @@ type Shortlink struct {
 	ShortCode     string  `json:"shortCode"`
 	RedirectCount int     `json:"redirectCount"`
 	Status        string  `json:"status"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	MaxClicks     *int       `json:"maxClicks"`
+	Protected     bool       `json:"protected"`
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
 }
 
+// IsExpired reports whether the link has passed its expiry date or used up
+// its click budget.
+func (sl Shortlink) IsExpired(now time.Time) bool {
+	if sl.ExpiresAt != nil && !now.Before(*sl.ExpiresAt) {
+		return true
+	}
+	return sl.MaxClicks != nil && sl.RedirectCount >= *sl.MaxClicks
+}
+
 
 type ShortlinkClick struct {
-	ID          int       `json:"id"`
-	ShortlinkID int       `json:"shortlinkId"`
-	IP          string    `json:"ip"`
-	UserAgent   string    `json:"userAgent"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID             int       `json:"id"`
+	ShortlinkID    int       `json:"shortlinkId"`
+	IP             string    `json:"ip"`
+	UserAgent      string    `json:"userAgent"`
+	Referrer       string    `json:"referrer"`
+	ReferrerDomain string    `json:"referrerDomain"`
+	Browser        string    `json:"browser"`
+	OS             string    `json:"os"`
+	Device         string    `json:"device"`
+	CountryCode    string    `json:"countryCode"`
+	RegionCode     string    `json:"regionCode"`
+	City           string    `json:"city"`
+	IsBot          bool      `json:"isBot"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// NewShortlinkClick builds a click with the referring domain, the parsed
+// browser, OS and device class, the GeoIP location and the bot flag filled in.
+// CreatedAt is the time of the request, not of the eventual insert.
+func NewShortlinkClick(shortlinkID int, method, ip, userAgent, referrer string) ShortlinkClick {
+	client := utils.ParseUserAgent(userAgent)
+	geo := utils.LookupGeo(ip)
+	return ShortlinkClick{
+		ShortlinkID:    shortlinkID,
+		IP:             ip,
+		UserAgent:      userAgent,
+		Referrer:       referrer,
+		ReferrerDomain: truncate(utils.ReferrerDomain(referrer), 255),
+		Browser:        truncate(client.Browser, 50),
+		OS:             truncate(client.OS, 50),
+		Device:         client.Device,
+		CountryCode:    truncate(geo.CountryCode, 2),
+		RegionCode:     truncate(geo.RegionCode, 10),
+		City:           truncate(geo.City, 100),
+		IsBot:          utils.IsBotRequest(method, userAgent),
+		CreatedAt:      time.Now(),
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
 }
 
 func CreateShortlink(db *pgxpool.Pool, sl Shortlink) (Shortlink, error) {
+    return insertShortlink(db, sl)
+}
+
+// utcTime converts t for a TIMESTAMP column such as an expires_at: pgx writes
+// the wall clock and drops the zone, so anything but UTC would shift it.
+func utcTime(t *time.Time) *time.Time {
+    if t == nil {
+        return nil
+    }
+    u := t.UTC()
+    return &u
+}
+
+func insertShortlink(q queryRower, sl Shortlink) (Shortlink, error) {
     if sl.Status == "" {
         sl.Status = "active"
     }
 
-    err := db.QueryRow(
+    err := q.QueryRow(
         context.Background(),
-        `INSERT INTO shortlinks (user_id, original_url, short_code, status)
-         VALUES ($1, $2, $3, $4)
-         RETURNING id, status, created_at, updated_at`,
-        sl.UserID, sl.OriginalURL, sl.ShortCode, sl.Status,
-    ).Scan(&sl.ID, &sl.Status, &sl.CreatedAt, &sl.UpdatedAt)
+        `INSERT INTO shortlinks (id, user_id, original_url, short_code, status, expires_at, max_clicks)
+         VALUES (COALESCE(NULLIF($1::int, 0), nextval(pg_get_serial_sequence('shortlinks', 'id'))), $2, $3, $4, $5, $6, $7)
+         RETURNING id, status, password_hash IS NOT NULL, created_at, updated_at`,
+        sl.ID, sl.UserID, sl.OriginalURL, sl.ShortCode, sl.Status, utcTime(sl.ExpiresAt), sl.MaxClicks,
+    ).Scan(&sl.ID, &sl.Status, &sl.Protected, &sl.CreatedAt, &sl.UpdatedAt)
 
     return sl, err
 }
 
+// CreateShortlinkWithGenerator inserts sl with a code from gen, retrying with
+// a fresh code whenever the short_code UNIQUE constraint is hit.
+func CreateShortlinkWithGenerator(db *pgxpool.Pool, sl Shortlink, gen utils.ShortCodeGenerator) (Shortlink, error) {
+	return createShortlinkWithGenerator(db, sl, gen, func(sl Shortlink) (Shortlink, error) {
+		return insertShortlink(db, sl)
+	})
+}
+
+func createShortlinkWithGenerator(q queryRower, sl Shortlink, gen utils.ShortCodeGenerator, insert func(Shortlink) (Shortlink, error)) (Shortlink, error) {
+	for attempt := 0; attempt < MaxShortCodeAttempts; attempt++ {
+		seq, err := nextShortlinkSeq(q)
+		if err != nil {
+			return sl, err
+		}
+
+		code, err := gen.Generate(utils.ShortCodeSource{Seq: seq, URL: sl.OriginalURL, Attempt: attempt})
+		if err != nil {
+			return sl, err
+		}
+		if utils.IsReservedShortCode(code) {
+			continue
+		}
+
+		sl.ID = int(seq)
+		sl.ShortCode = code
+		created, err := insert(sl)
+		if IsShortCodeConflict(err) {
+			continue
+		}
+		return created, err
+	}
+	return sl, ErrShortCodeExhausted
+}
+
+// UpdateShortlinkWithGenerator is UpdateShortlink with a freshly generated
+// code, retried the same way as CreateShortlinkWithGenerator.
+func UpdateShortlinkWithGenerator(db *pgxpool.Pool, sl Shortlink, gen utils.ShortCodeGenerator) (Shortlink, error) {
+	for attempt := 0; attempt < MaxShortCodeAttempts; attempt++ {
+		seq, err := nextShortlinkSeq(db)
+		if err != nil {
+			return sl, err
+		}
+
+		code, err := gen.Generate(utils.ShortCodeSource{Seq: seq, URL: sl.OriginalURL, Attempt: attempt})
+		if err != nil {
+			return sl, err
+		}
+		if utils.IsReservedShortCode(code) || code == sl.ShortCode {
+			continue
+		}
+
+		next := sl
+		next.ShortCode = code
+		updated, err := UpdateShortlink(db, next)
+		if IsShortCodeConflict(err) {
+			continue
+		}
+		return updated, err
+	}
+	return sl, ErrShortCodeExhausted
+}
+
+type BulkShortlinkResult struct {
+	Shortlink Shortlink
+	Err       error
+}
+
+// CreateShortlinksBulk inserts all links in one transaction. Each row runs
+// in its own savepoint so a failing row is reported in its result without
+// aborting the others. Links with a ShortCode keep it, the rest get one
+// from gen.
+func CreateShortlinksBulk(db *pgxpool.Pool, links []Shortlink, gen utils.ShortCodeGenerator) ([]BulkShortlinkResult, error) {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	insert := func(sl Shortlink) (Shortlink, error) {
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return sl, err
+		}
+		created, err := insertShortlink(sp, sl)
+		if err != nil {
+			sp.Rollback(ctx)
+			return sl, err
+		}
+		return created, sp.Commit(ctx)
+	}
+
+	results := make([]BulkShortlinkResult, len(links))
+	for i, sl := range links {
+		var created Shortlink
+		if sl.ShortCode != "" {
+			created, err = insert(sl)
+		} else {
+			created, err = createShortlinkWithGenerator(tx, sl, gen, insert)
+		}
+		results[i] = BulkShortlinkResult{Shortlink: created, Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func nextShortlinkSeq(q queryRower) (int64, error) {
+	var seq int64
+	err := q.QueryRow(context.Background(),
+		`SELECT nextval(pg_get_serial_sequence('shortlinks', 'id'))`,
+	).Scan(&seq)
+	return seq, err
+}
+
+func IsShortCodeConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "shortlinks_short_code_key"
+}
+
 
 func GetAllShortlinks(db *pgxpool.Pool, userID int64, limit, offset int) ([]Shortlink, int, error) {
 	var total int
@@ -54,7 +253,7 @@ func GetAllShortlinks(db *pgxpool.Pool, userID int64, limit, offset int) ([]Shor
 	}
 
 	rows, err := db.Query(context.Background(),
-		`SELECT id, user_id, original_url, short_code, redirect_count, created_at, updated_at, status 
+		`SELECT id, user_id, original_url, short_code, redirect_count, created_at, updated_at, status, expires_at, max_clicks, password_hash IS NOT NULL 
 		 FROM shortlinks 
 		 WHERE user_id=$1 
 		 ORDER BY created_at DESC 
@@ -67,7 +266,7 @@ func GetAllShortlinks(db *pgxpool.Pool, userID int64, limit, offset int) ([]Shor
 	var result []Shortlink
 	for rows.Next() {
 		var sl Shortlink
-		if err := rows.Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.CreatedAt, &sl.UpdatedAt, &sl.Status); err != nil {
+		if err := rows.Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.CreatedAt, &sl.UpdatedAt, &sl.Status, &sl.ExpiresAt, &sl.MaxClicks, &sl.Protected); err != nil {
 			return nil, 0, err
 		}
 		result = append(result, sl)
@@ -80,46 +279,72 @@ func GetShortlinkByCode(db *pgxpool.Pool, code string) (Shortlink, error) {
 	var sl Shortlink
 	err := db.QueryRow(
 		context.Background(),
-		`SELECT id, user_id, original_url, short_code, redirect_count, status, created_at, updated_at 
+		`SELECT id, user_id, original_url, short_code, redirect_count, status, expires_at, max_clicks, password_hash IS NOT NULL, created_at, updated_at 
 		 FROM shortlinks WHERE short_code=$1`,
 		code,
-	).Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.Status, &sl.CreatedAt, &sl.UpdatedAt)
+	).Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.Status, &sl.ExpiresAt, &sl.MaxClicks, &sl.Protected, &sl.CreatedAt, &sl.UpdatedAt)
 	return sl, err
 }
 
-func IncrementRedirectCount(db *pgxpool.Pool, shortlinkID int) error {
-	_, err := db.Exec(
+func GetShortlinkPasswordHash(db *pgxpool.Pool, code string) (string, error) {
+	var hash *string
+	err := db.QueryRow(
 		context.Background(),
-		`UPDATE shortlinks 
-		 SET redirect_count = redirect_count + 1, updated_at = now() 
-		 WHERE id=$1`,
-		shortlinkID,
-	)
-	if err == nil {
-		utils.RedisClient.Del(context.Background(), "analytics:global:7d")
+		`SELECT password_hash FROM shortlinks WHERE short_code=$1`,
+		code,
+	).Scan(&hash)
+	if err != nil {
+		return "", err
 	}
-	return err
+	if hash == nil {
+		return "", nil
+	}
+	return *hash, nil
 }
 
-func LogClick(db *pgxpool.Pool, click ShortlinkClick) error {
+// SetShortlinkPassword stores an argon2 hash for the link, or removes the
+// password when hash is nil.
+func SetShortlinkPassword(db *pgxpool.Pool, shortlinkID int, hash *string) error {
 	_, err := db.Exec(
 		context.Background(),
-		`INSERT INTO shortlink_clicks (shortlink_id, ip_address, user_agent, clicked_at) 
-		 VALUES ($1, $2, $3, now())`,
-		click.ShortlinkID, click.IP, click.UserAgent,
+		`UPDATE shortlinks SET password_hash=$1, updated_at=now() WHERE id=$2`,
+		hash, shortlinkID,
 	)
 	return err
 }
 
+// ConsumeClick counts a redirect against a link with a click budget and
+// returns the new redirect_count. It returns false without counting when the
+// budget is already used up.
+func ConsumeClick(db *pgxpool.Pool, shortlinkID int) (int, bool, error) {
+	var total int
+	err := db.QueryRow(
+		context.Background(),
+		`UPDATE shortlinks 
+		 SET redirect_count = redirect_count + 1, updated_at = now() 
+		 WHERE id=$1 AND (max_clicks IS NULL OR redirect_count < max_clicks)
+		 RETURNING redirect_count`,
+		shortlinkID,
+	).Scan(&total)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	utils.RedisClient.Del(context.Background(), "analytics:global:7d")
+	return total, true, nil
+}
+
 func UpdateShortlink(db *pgxpool.Pool, sl Shortlink) (Shortlink, error) {
 	err := db.QueryRow(
 		context.Background(),
 		`UPDATE shortlinks 
-		 SET original_url=$1, short_code=$2, status=$3, updated_at=now() 
-		 WHERE id=$4
-		 RETURNING id, user_id, original_url, short_code, redirect_count, status, created_at, updated_at`,
-		sl.OriginalURL, sl.ShortCode, sl.Status, sl.ID,
-	).Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.Status, &sl.CreatedAt, &sl.UpdatedAt)
+		 SET original_url=$1, short_code=$2, status=$3, expires_at=$4, max_clicks=$5, updated_at=now() 
+		 WHERE id=$6
+		 RETURNING id, user_id, original_url, short_code, redirect_count, status, expires_at, max_clicks, password_hash IS NOT NULL, created_at, updated_at`,
+		sl.OriginalURL, sl.ShortCode, sl.Status, utcTime(sl.ExpiresAt), sl.MaxClicks, sl.ID,
+	).Scan(&sl.ID, &sl.UserID, &sl.OriginalURL, &sl.ShortCode, &sl.RedirectCount, &sl.Status, &sl.ExpiresAt, &sl.MaxClicks, &sl.Protected, &sl.CreatedAt, &sl.UpdatedAt)
 	return sl, err
 }
 
@@ -144,17 +369,71 @@ func DeleteShortlink(db *pgxpool.Pool, shortCode string) error {
 }
 
 type DailyVisit struct {
-	Date   string `json:"date"`
-	Visits int    `json:"visits"`
+	Date         string `json:"date"`
+	Visits       int    `json:"visits"`
+	UniqueVisits int    `json:"uniqueVisits"`
 }
 
 type DashboardStats struct {
-	TotalLinks          int          `json:"totalLinks"`
-	TotalVisits         int          `json:"totalVisits"`
-	AvgClickRate        float64      `json:"avgClickRate"`
-	VisitsGrowth        float64      `json:"visitsGrowth"`
-	Last7Days           []DailyVisit `json:"last7Days"`
-	Last7DaysShortlinks []Shortlink  `json:"last7DaysShortlinks"`
+	From                time.Time       `json:"from"`
+	To                  time.Time       `json:"to"`
+	Granularity         string          `json:"granularity,omitempty"`
+	Timezone            string          `json:"timezone,omitempty"`
+	TotalLinks          int             `json:"totalLinks"`
+	TotalVisits         int             `json:"totalVisits"`
+	UniqueVisits        int             `json:"uniqueVisits"`
+	RangeVisits         int             `json:"rangeVisits"`
+	RangeUniqueVisits   int             `json:"rangeUniqueVisits"`
+	AvgClickRate        float64         `json:"avgClickRate"`
+	VisitsGrowth        float64         `json:"visitsGrowth"`
+	Series              []TimeBucket    `json:"series,omitempty"`
+	Last7Days           []DailyVisit    `json:"last7Days"`
+	Last7DaysShortlinks []Shortlink     `json:"last7DaysShortlinks"`
+	Breakdowns          ClickBreakdowns `json:"breakdowns"`
+}
+
+// DashboardRange is the window of GetDashboardStatsByUser. Buckets start at
+// midnight (or the hour, or Monday) in Location.
+type DashboardRange struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	Location    *time.Location
+	IncludeBots bool
+}
+
+// LastDaysRange covers the last days days by day, ending with today in loc.
+func LastDaysRange(days int, loc *time.Location) DashboardRange {
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	return DashboardRange{
+		From:        to.AddDate(0, 0, -days),
+		To:          to,
+		Granularity: "day",
+		Location:    loc,
+	}
+}
+
+// isLastDays reports whether r is LastDaysRange(days) in its own location.
+func (r DashboardRange) isLastDays(days int) bool {
+	last := LastDaysRange(days, r.Location)
+	return r.Granularity == last.Granularity && r.From.Equal(last.From) && r.To.Equal(last.To)
+}
+
+// dailyVisits turns a series by day into the older last7Days shape.
+func dailyVisits(series []TimeBucket) []DailyVisit {
+	days := make([]DailyVisit, 0, len(series))
+	for _, b := range series {
+		d := DailyVisit{
+			Date:   b.Bucket.Format("2006-01-02"),
+			Visits: b.Clicks,
+		}
+		if b.UniqueVisits != nil {
+			d.UniqueVisits = *b.UniqueVisits
+		}
+		days = append(days, d)
+	}
+	return days
 }
 
 func GetDashboardStats(db *pgxpool.Pool) (DashboardStats, error) {
@@ -174,6 +453,12 @@ func GetDashboardStats(db *pgxpool.Pool) (DashboardStats, error) {
 		return stats, err
 	}
 
+	uniques, err := utils.CountUniqueVisits(context.Background(), utils.GlobalUniqueAllKey())
+	if err != nil {
+		return stats, err
+	}
+	stats.UniqueVisits = int(uniques)
+
 	if stats.TotalLinks > 0 {
 		stats.AvgClickRate = float64(stats.TotalVisits) / float64(stats.TotalLinks)
 	}
@@ -217,31 +502,115 @@ func GetDashboardStats(db *pgxpool.Pool) (DashboardStats, error) {
 			day.Format("2006-01-02"),
 		).Scan(&count)
 
+		dayUniques, _ := utils.CountUniqueVisits(context.Background(), utils.GlobalUniqueKey(day))
+
 		stats.Last7Days[i] = DailyVisit{
-			Date:   day.Format("2006-01-02"),
-			Visits: count,
+			Date:         day.Format("2006-01-02"),
+			Visits:       count,
+			UniqueVisits: int(dayUniques),
 		}
 	}
 
 	return stats, nil
 }
 
-func GetDashboardStatsByUser(db *pgxpool.Pool, userID int) (DashboardStats, error) {
-	var stats DashboardStats
+// GetDashboardStatsByUser aggregates the user's links and clicks in a single
+// query: all-time totals, visits in the range and in the equally long range
+// before it, and the visits per bucket. clicked_at holds UTC wall-clock time.
+// Days before the rollup watermark are read from shortlink_daily_stats; for
+// ranges that don't fall on UTC days the range itself is counted from raw
+// clicks.
+// Clicks flagged as bots are left out unless r.IncludeBots is set; unique
+// visits never count them.
+func GetDashboardStatsByUser(db *pgxpool.Pool, userID int, r DashboardRange) (DashboardStats, error) {
+	ctx := context.Background()
+	from, to := r.From.UTC(), r.To.UTC()
+	stats := DashboardStats{
+		From:                r.From,
+		To:                  r.To,
+		Granularity:         r.Granularity,
+		Timezone:            r.Location.String(),
+		Series:              []TimeBucket{},
+		Last7Days:           []DailyVisit{},
+		Last7DaysShortlinks: []Shortlink{},
+	}
 
-	err := db.QueryRow(context.Background(),
-		`SELECT COUNT(*) FROM shortlinks WHERE user_id=$1`, userID,
-	).Scan(&stats.TotalLinks)
+	// Without rollups the range and the one before it, which visitsGrowth
+	// compares against, are counted from raw clicks alone.
+	useRollups := canUseRollups(r.From, r.To, r.Location, r.Granularity)
+	if !useRollups {
+		if err := CheckRawClicksKept(db, from.Add(-to.Sub(from))); err != nil {
+			return stats, err
+		}
+	}
+
+	rows, err := db.Query(ctx,
+		`WITH wm AS (`+rollupWatermarkSQL+`),
+		 links AS (
+		     SELECT id FROM shortlinks WHERE user_id=$1
+		 ),
+		 rolled AS (
+		     SELECT d.day::timestamp AS clicked_at, d.clicks + CASE WHEN $6 THEN d.bots ELSE 0 END AS n
+		     FROM shortlink_daily_stats d, wm
+		     WHERE d.shortlink_id IN (SELECT id FROM links) AND d.day < wm.day
+		 ),
+		 recent AS (
+		     SELECT c.clicked_at, 1 AS n
+		     FROM shortlink_clicks c, wm
+		     WHERE c.shortlink_id IN (SELECT id FROM links) AND ($6 OR NOT c.is_bot)
+		     AND c.clicked_at >= wm.day
+		 ),
+		 ranged AS (
+		     SELECT clicked_at, n FROM rolled WHERE $7
+		     UNION ALL
+		     SELECT clicked_at, n FROM recent
+		     UNION ALL
+		     SELECT c.clicked_at, 1
+		     FROM shortlink_clicks c, wm
+		     WHERE NOT $7 AND c.shortlink_id IN (SELECT id FROM links) AND ($6 OR NOT c.is_bot)
+		     AND c.clicked_at < wm.day
+		     AND c.clicked_at >= $2::timestamp - ($3::timestamp - $2::timestamp) AND c.clicked_at < $3::timestamp
+		 ),
+		 totals AS (
+		     SELECT (SELECT COUNT(*) FROM links) AS total_links,
+		            (SELECT COALESCE(SUM(n), 0) FROM rolled) + (SELECT COUNT(*) FROM recent) AS total_visits,
+		            COALESCE(SUM(n) FILTER (WHERE clicked_at >= $2::timestamp AND clicked_at < $3::timestamp), 0) AS range_visits,
+		            COALESCE(SUM(n) FILTER (WHERE clicked_at >= $2::timestamp - ($3::timestamp - $2::timestamp) AND clicked_at < $2::timestamp), 0) AS previous_visits
+		     FROM ranged
+		 ),
+		 series AS (
+		     SELECT b.bucket, COALESCE(SUM(c.n), 0) AS visits
+		     FROM generate_series(
+		         date_trunc($4, timezone($5, timezone('UTC', $2::timestamp))),
+		         timezone($5, timezone('UTC', $3::timestamp - interval '1 microsecond')),
+		         ('1 ' || $4)::interval
+		     ) AS b(bucket)
+		     LEFT JOIN ranged c
+		       ON c.clicked_at >= $2::timestamp AND c.clicked_at < $3::timestamp
+		      AND date_trunc($4, timezone($5, timezone('UTC', c.clicked_at))) = b.bucket
+		     GROUP BY b.bucket
+		 )
+		 SELECT timezone($5, s.bucket), s.visits, t.total_links, t.total_visits, t.range_visits, t.previous_visits
+		 FROM series s
+		 CROSS JOIN totals t
+		 ORDER BY s.bucket`,
+		userID, from, to, r.Granularity, r.Location.String(), r.IncludeBots, useRollups,
+	)
 	if err != nil {
 		return stats, err
 	}
+	defer rows.Close()
 
-	err = db.QueryRow(context.Background(),
-		`SELECT COUNT(*) FROM shortlink_clicks 
-		 WHERE shortlink_id IN (SELECT id FROM shortlinks WHERE user_id=$1)`,
-		userID,
-	).Scan(&stats.TotalVisits)
-	if err != nil {
+	var previousVisits int
+	for rows.Next() {
+		var b TimeBucket
+		if err := rows.Scan(&b.Bucket, &b.Clicks, &stats.TotalLinks, &stats.TotalVisits, &stats.RangeVisits, &previousVisits); err != nil {
+			return stats, err
+		}
+		b.Bucket = b.Bucket.In(r.Location)
+		stats.Series = append(stats.Series, b)
+	}
+	if err := rows.Err(); err != nil {
 		return stats, err
 	}
 
@@ -249,50 +618,42 @@ func GetDashboardStatsByUser(db *pgxpool.Pool, userID int) (DashboardStats, erro
 		stats.AvgClickRate = float64(stats.TotalVisits) / float64(stats.TotalLinks)
 	}
 
-	now := time.Now()
-	weekStart := now.AddDate(0, 0, -7)
+	if previousVisits > 0 {
+		stats.VisitsGrowth = float64(stats.RangeVisits-previousVisits) / float64(previousVisits) * 100
+	} else if stats.RangeVisits > 0 {
+		stats.VisitsGrowth = 100
+	}
 
-	var thisWeek, lastWeek int
-	err = db.QueryRow(context.Background(),
-		`SELECT COUNT(*) FROM shortlink_clicks 
-		 WHERE shortlink_id IN (SELECT id FROM shortlinks WHERE user_id=$1)
-		 AND clicked_at >= $2`, userID, weekStart,
-	).Scan(&thisWeek)
+	uniques, err := utils.CountUniqueVisits(ctx, utils.UserUniqueAllKey(int64(userID)))
 	if err != nil {
-		thisWeek = 0
+		return stats, err
 	}
+	stats.UniqueVisits = int(uniques)
 
-	lastWeekStart := weekStart.AddDate(0, 0, -7)
-	lastWeekEnd := weekStart
-	err = db.QueryRow(context.Background(),
-		`SELECT COUNT(*) FROM shortlink_clicks 
-		 WHERE shortlink_id IN (SELECT id FROM shortlinks WHERE user_id=$1)
-		 AND clicked_at >= $2 AND clicked_at < $3`, userID, lastWeekStart, lastWeekEnd,
-	).Scan(&lastWeek)
+	// The HyperLogLogs are kept per UTC day, so unique counts of ranges and
+	// buckets in other time zones are rounded out to whole UTC days.
+	keysBetween := func(from, to time.Time) []string { return utils.UserUniqueKeysBetween(int64(userID), from, to) }
+	rangeUniques, err := utils.CountUniqueVisits(ctx, keysBetween(from, to)...)
 	if err != nil {
-		lastWeek = 0
+		return stats, err
 	}
+	stats.RangeUniqueVisits = int(rangeUniques)
 
-	if lastWeek > 0 {
-		stats.VisitsGrowth = float64(thisWeek-lastWeek) / float64(lastWeek) * 100
-	} else if thisWeek > 0 {
-		stats.VisitsGrowth = 100
+	if err := fillSeriesUniques(ctx, stats.Series, r.Granularity, keysBetween); err != nil {
+		return stats, err
 	}
 
-	stats.Last7Days = make([]DailyVisit, 7)
-	for i := 0; i < 7; i++ {
-		day := now.AddDate(0, 0, -6+i)  
-		var count int
-		_ = db.QueryRow(context.Background(),
-			`SELECT COUNT(*) FROM shortlink_clicks 
-			 WHERE shortlink_id IN (SELECT id FROM shortlinks WHERE user_id=$1)
-			 AND DATE(clicked_at) = $2`, userID, day.Format("2006-01-02"),
-		).Scan(&count)
+	// Clients written before series existed read last7Days, so the default
+	// range still fills it in.
+	if r.isLastDays(7) {
+		stats.Last7Days = dailyVisits(stats.Series)
+	}
 
-		stats.Last7Days[i] = DailyVisit{
-			Date:   day.Format("Jan 02"), 
-			Visits: count,
-		}
+	stats.Breakdowns, err = GetClickBreakdowns(db,
+		`shortlink_id IN (SELECT id FROM shortlinks WHERE user_id=$1)
+		 AND clicked_at >= $2 AND clicked_at < $3 AND ($4 OR NOT is_bot)`, userID, from, to, r.IncludeBots)
+	if err != nil {
+		return stats, err
 	}
 
 	return stats, nil
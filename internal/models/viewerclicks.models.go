@@ -0,0 +1,92 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ViewerClick struct {
+	UserID         int64     `json:"userId"`
+	Fullname       string    `json:"fullname"`
+	Email          string    `json:"email"`
+	Clicks         int       `json:"clicks"`
+	FirstClickedAt time.Time `json:"firstClickedAt"`
+	LastClickedAt  time.Time `json:"lastClickedAt"`
+}
+
+// ViewerClickCount is one flushed viewers:link:<id>:user:<id> counter.
+type ViewerClickCount struct {
+	ShortlinkID int
+	UserID      int64
+	Clicks      int
+}
+
+// AddViewerClicks adds the counters to shortlink_viewer_clicks. Counters of
+// links or users that no longer exist are skipped.
+func AddViewerClicks(db *pgxpool.Pool, counts []ViewerClickCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	linkIDs := make([]int32, len(counts))
+	userIDs := make([]int64, len(counts))
+	clicks := make([]int32, len(counts))
+	for i, c := range counts {
+		linkIDs[i] = int32(c.ShortlinkID)
+		userIDs[i] = c.UserID
+		clicks[i] = int32(c.Clicks)
+	}
+
+	_, err := db.Exec(context.Background(),
+		`INSERT INTO shortlink_viewer_clicks (shortlink_id, user_id, clicks, first_clicked_at, last_clicked_at)
+		 SELECT s.id, u.id, SUM(v.n), now(), now()
+		 FROM unnest($1::int[], $2::bigint[], $3::int[]) AS v(shortlink_id, user_id, n)
+		 JOIN shortlinks s ON s.id = v.shortlink_id
+		 JOIN users u ON u.id = v.user_id
+		 GROUP BY s.id, u.id
+		 ON CONFLICT (shortlink_id, user_id) DO UPDATE
+		 SET clicks = shortlink_viewer_clicks.clicks + EXCLUDED.clicks,
+		     last_clicked_at = EXCLUDED.last_clicked_at`,
+		linkIDs, userIDs, clicks,
+	)
+	return err
+}
+
+// GetShortlinkViewers lists the logged-in users who opened a link, most
+// recent first.
+func GetShortlinkViewers(db *pgxpool.Pool, shortlinkID int, limit, offset int) ([]ViewerClick, int, error) {
+	var total int
+	err := db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM shortlink_viewer_clicks WHERE shortlink_id=$1`, shortlinkID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(context.Background(),
+		`SELECT v.user_id, u.fullname, u.email, v.clicks, v.first_clicked_at, v.last_clicked_at
+		 FROM shortlink_viewer_clicks v
+		 JOIN users u ON u.id = v.user_id
+		 WHERE v.shortlink_id=$1
+		 ORDER BY v.last_clicked_at DESC
+		 LIMIT $2 OFFSET $3`,
+		shortlinkID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	viewers := []ViewerClick{}
+	for rows.Next() {
+		var v ViewerClick
+		if err := rows.Scan(&v.UserID, &v.Fullname, &v.Email, &v.Clicks, &v.FirstClickedAt, &v.LastClickedAt); err != nil {
+			return nil, 0, err
+		}
+		viewers = append(viewers, v)
+	}
+
+	return viewers, total, rows.Err()
+}
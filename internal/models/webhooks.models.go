@@ -0,0 +1,345 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	WebhookEventLinkCreated   = "link.created"
+	WebhookEventLinkUpdated   = "link.updated"
+	WebhookEventLinkDeleted   = "link.deleted"
+	WebhookEventLinkClicked   = "link.clicked"
+	WebhookEventLinkMilestone = "link.milestone"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []string{
+	WebhookEventLinkCreated,
+	WebhookEventLinkUpdated,
+	WebhookEventLinkDeleted,
+	WebhookEventLinkClicked,
+	WebhookEventLinkMilestone,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID        int       `json:"id"`
+	UserID    int64     `json:"userId"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int             `json:"webhookId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt"`
+	ResponseStatus *int            `json:"responseStatus"`
+	ResponseBody   *string         `json:"responseBody"`
+	LastError      *string         `json:"lastError"`
+	ReplayOf       *int64          `json:"replayOf"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// PendingWebhookDelivery is a claimed delivery together with where and how
+// to send it.
+type PendingWebhookDelivery struct {
+	ID        int64
+	WebhookID int
+	Event     string
+	Payload   json.RawMessage
+	Attempts  int
+	CreatedAt time.Time
+	URL       string
+	Secret    string
+}
+
+// WebhookAttempt is the outcome of one delivery attempt. A failed attempt
+// with a RetryIn of zero gives up on the delivery.
+type WebhookAttempt struct {
+	Delivered      bool
+	ResponseStatus *int
+	ResponseBody   string
+	Error          string
+	RetryIn        time.Duration
+}
+
+const webhookColumns = `id, user_id, url, secret, events, active, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event, payload, status, attempts,
+	CASE WHEN status = 'pending' THEN next_attempt_at END, last_attempt_at,
+	response_status, response_body, last_error, replay_of, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row rowScanner) (Webhook, error) {
+	var w Webhook
+	err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.Secret, &w.Events, &w.Active, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+func scanWebhookDelivery(row rowScanner) (WebhookDelivery, error) {
+	var d WebhookDelivery
+	err := row.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.ResponseBody, &d.LastError, &d.ReplayOf, &d.CreatedAt)
+	return d, err
+}
+
+func CreateWebhook(db *pgxpool.Pool, w Webhook) (Webhook, error) {
+	return scanWebhook(db.QueryRow(context.Background(),
+		`INSERT INTO webhooks (user_id, url, secret, events, active)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING `+webhookColumns,
+		w.UserID, w.URL, w.Secret, w.Events, w.Active,
+	))
+}
+
+func CountWebhooksByUser(db *pgxpool.Pool, userID int64) (int, error) {
+	var n int
+	err := db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM webhooks WHERE user_id=$1`, userID,
+	).Scan(&n)
+	return n, err
+}
+
+// GetWebhooksByUser lists a user's webhooks without their secrets.
+func GetWebhooksByUser(db *pgxpool.Pool, userID int64) ([]Webhook, error) {
+	rows, err := db.Query(context.Background(),
+		`SELECT `+webhookColumns+` FROM webhooks WHERE user_id=$1 ORDER BY id`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		w.Secret = ""
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetWebhookByID returns pgx.ErrNoRows when the webhook does not exist or
+// belongs to someone else.
+func GetWebhookByID(db *pgxpool.Pool, id int, userID int64) (Webhook, error) {
+	return scanWebhook(db.QueryRow(context.Background(),
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id=$1 AND user_id=$2`, id, userID,
+	))
+}
+
+func UpdateWebhook(db *pgxpool.Pool, w Webhook) (Webhook, error) {
+	return scanWebhook(db.QueryRow(context.Background(),
+		`UPDATE webhooks SET url=$3, events=$4, active=$5, updated_at=now()
+		 WHERE id=$1 AND user_id=$2
+		 RETURNING `+webhookColumns,
+		w.ID, w.UserID, w.URL, w.Events, w.Active,
+	))
+}
+
+func DeleteWebhook(db *pgxpool.Pool, id int, userID int64) (bool, error) {
+	tag, err := db.Exec(context.Background(),
+		`DELETE FROM webhooks WHERE id=$1 AND user_id=$2`, id, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetSubscribedWebhookEvents returns the distinct events the user's active
+// webhooks listen to.
+func GetSubscribedWebhookEvents(db *pgxpool.Pool, userID int64) ([]string, error) {
+	rows, err := db.Query(context.Background(),
+		`SELECT DISTINCT unnest(events) FROM webhooks WHERE user_id=$1 AND active`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []string{}
+	for rows.Next() {
+		var event string
+		if err := rows.Scan(&event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// EnqueueWebhookEvent queues one delivery per active webhook of the user
+// subscribed to event and returns how many were queued.
+func EnqueueWebhookEvent(db *pgxpool.Pool, userID int64, event string, payload []byte) (int64, error) {
+	tag, err := db.Exec(context.Background(),
+		`INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		 SELECT id, $2, $3::jsonb FROM webhooks
+		 WHERE user_id=$1 AND active AND $2 = ANY(events)`,
+		userID, event, string(payload),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetWebhookDeliveries lists a webhook's deliveries, newest first. An empty
+// status lists all of them.
+func GetWebhookDeliveries(db *pgxpool.Pool, webhookID int, status string, limit, offset int) ([]WebhookDelivery, int, error) {
+	var total int
+	err := db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id=$1 AND ($2 = '' OR status = $2)`,
+		webhookID, status,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(context.Background(),
+		`SELECT `+webhookDeliveryColumns+`
+		 FROM webhook_deliveries
+		 WHERE webhook_id=$1 AND ($2 = '' OR status = $2)
+		 ORDER BY id DESC
+		 LIMIT $3 OFFSET $4`,
+		webhookID, status, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, total, rows.Err()
+}
+
+// ReplayWebhookDelivery queues a new delivery with the payload of an earlier
+// one, leaving the original in the log. It returns pgx.ErrNoRows when the
+// delivery does not belong to the webhook.
+func ReplayWebhookDelivery(db *pgxpool.Pool, webhookID int, deliveryID int64) (WebhookDelivery, error) {
+	return scanWebhookDelivery(db.QueryRow(context.Background(),
+		`INSERT INTO webhook_deliveries (webhook_id, event, payload, replay_of)
+		 SELECT webhook_id, event, payload, id FROM webhook_deliveries
+		 WHERE id=$1 AND webhook_id=$2
+		 RETURNING `+webhookDeliveryColumns,
+		deliveryID, webhookID,
+	))
+}
+
+// ClaimWebhookDeliveries takes up to limit due deliveries of active webhooks
+// that have had fewer than maxAttempts attempts, and counts the attempt.
+// Claimed rows are pushed back by lease, so a dispatcher that dies
+// mid-delivery only delays them.
+func ClaimWebhookDeliveries(db *pgxpool.Pool, limit, maxAttempts int, lease time.Duration) ([]PendingWebhookDelivery, error) {
+	rows, err := db.Query(context.Background(),
+		`UPDATE webhook_deliveries d
+		 SET attempts = d.attempts + 1,
+		     last_attempt_at = now(),
+		     next_attempt_at = now() + $2::float8 * interval '1 second'
+		 FROM webhooks w
+		 WHERE w.id = d.webhook_id
+		 AND d.id IN (
+		     SELECT q.id FROM webhook_deliveries q
+		     JOIN webhooks qw ON qw.id = q.webhook_id
+		     WHERE q.status = 'pending' AND q.next_attempt_at <= now() AND qw.active
+		     AND q.attempts < $3
+		     ORDER BY q.next_attempt_at
+		     LIMIT $1
+		     FOR UPDATE OF q SKIP LOCKED
+		 )
+		 RETURNING d.id, d.webhook_id, d.event, d.payload, d.attempts, d.created_at, w.url, w.secret`,
+		limit, lease.Seconds(), maxAttempts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []PendingWebhookDelivery
+	for rows.Next() {
+		var d PendingWebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Attempts, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, d)
+	}
+	return claimed, rows.Err()
+}
+
+// RecordWebhookAttempt stores the outcome of an attempt and either marks the
+// delivery done or schedules the retry.
+func RecordWebhookAttempt(db *pgxpool.Pool, id int64, a WebhookAttempt) error {
+	status := WebhookDeliveryPending
+	switch {
+	case a.Delivered:
+		status = WebhookDeliveryDelivered
+	case a.RetryIn <= 0:
+		status = WebhookDeliveryFailed
+	}
+
+	_, err := db.Exec(context.Background(),
+		`UPDATE webhook_deliveries
+		 SET status=$2, response_status=$3, response_body=$4, last_error=$5,
+		     next_attempt_at = now() + $6::float8 * interval '1 second'
+		 WHERE id=$1`,
+		id, status, a.ResponseStatus, cleanText(a.ResponseBody), nullIfEmpty(a.Error), a.RetryIn.Seconds(),
+	)
+	return err
+}
+
+// FinishWebhookDelivery ends a delivery whose attempt could not be recorded,
+// so it isn't claimed and sent again.
+func FinishWebhookDelivery(db *pgxpool.Pool, id int64, delivered bool, reason string) error {
+	status := WebhookDeliveryFailed
+	if delivered {
+		status = WebhookDeliveryDelivered
+	}
+	_, err := db.Exec(context.Background(),
+		`UPDATE webhook_deliveries SET status=$2, response_body=NULL, last_error=$3 WHERE id=$1`,
+		id, status, nullIfEmpty(reason),
+	)
+	return err
+}
+
+// PurgeWebhookDeliveries drops finished deliveries older than olderThan.
+func PurgeWebhookDeliveries(db *pgxpool.Pool, olderThan time.Duration) (int64, error) {
+	tag, err := db.Exec(context.Background(),
+		`DELETE FROM webhook_deliveries
+		 WHERE status <> 'pending' AND created_at < now() - $1::float8 * interval '1 second'`,
+		olderThan.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,26 @@
+package routers
+
+import (
+	"koda-shortlink/internal/handler"
+	"koda-shortlink/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func AdminRoutes(r *gin.Engine, pg *pgxpool.Pool) {
+	adminController := handler.AdminController{DB: pg}
+
+	admin := r.Group("/api/v1/admin")
+	admin.Use(middleware.AuthMiddleware("admin"))
+	{
+		admin.GET("/stats", adminController.GetGlobalStats)
+		admin.GET("/users", adminController.ListUsers)
+		admin.POST("/users/:id/suspend", adminController.SuspendUser)
+		admin.DELETE("/users/:id/suspend", adminController.UnsuspendUser)
+		admin.PUT("/users/:id/role", adminController.SetUserRole)
+		admin.GET("/links", adminController.ListShortlinks)
+		admin.POST("/links/:shortCode/disable", adminController.DisableShortlink)
+		admin.DELETE("/links/:shortCode/disable", adminController.EnableShortlink)
+	}
+}
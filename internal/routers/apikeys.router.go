@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"koda-shortlink/internal/handler"
+	"koda-shortlink/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APIKeyRoutes only take JWTs, so a leaked key can't mint more keys.
+func APIKeyRoutes(r *gin.Engine, pg *pgxpool.Pool) {
+	apiKeyController := handler.APIKeyController{DB: pg}
+
+	apiKeys := r.Group("/api/v1/api-keys")
+	apiKeys.Use(middleware.AuthMiddleware(""))
+	{
+		apiKeys.POST("", apiKeyController.CreateAPIKey)
+		apiKeys.GET("", apiKeyController.GetAPIKeys)
+		apiKeys.DELETE("/:id", apiKeyController.DeleteAPIKey)
+	}
+}
@@ -2,6 +2,8 @@ package routers
 
 import (
 	"koda-shortlink/internal/handler"
+	"koda-shortlink/internal/middleware"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,6 +17,11 @@ func AuthRoutes(r *gin.Engine, pg *pgxpool.Pool) {
 		auth.POST("/register", authController.Register)
 		auth.POST("/login", authController.Login)
 		auth.POST("/logout", authController.Logout)
+		auth.POST("/logout-all", middleware.AuthMiddleware(""), authController.LogoutAll)
 		auth.POST("/refresh", authController.RefreshToken)
+		auth.POST("/forgot-password", middleware.RateLimitMiddleware(5, 15*time.Minute), authController.ForgotPassword)
+		auth.POST("/reset-password", middleware.RateLimitMiddleware(10, 15*time.Minute), authController.ResetPassword)
+		auth.POST("/verify-email", middleware.RateLimitMiddleware(10, 15*time.Minute), authController.VerifyEmail)
+		auth.POST("/resend-verification", middleware.AuthMiddleware(""), middleware.RateLimitMiddleware(3, 15*time.Minute), authController.ResendVerification)
 	}
 }
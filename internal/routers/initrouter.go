@@ -23,5 +23,10 @@ func InitRouter(pg *pgxpool.Pool) *gin.Engine {
 	AuthRoutes(r, pg)
 	ShortlinkRoutes(r, pg)
 	UserRoutes(r, pg)
+	MetricsRoutes(r)
+	WebhookRoutes(r, pg)
+	AdminRoutes(r, pg)
+	SessionRoutes(r, pg)
+	APIKeyRoutes(r, pg)
 	return r
 }
\ No newline at end of file
@@ -0,0 +1,17 @@
+package routers
+
+import (
+	"koda-shortlink/internal/handler"
+	"koda-shortlink/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func MetricsRoutes(r *gin.Engine) {
+	metricsController := handler.MetricsController{}
+
+	metrics := r.Group("/api/v1/metrics")
+	{
+		metrics.GET("/clicks", middleware.AuthMiddleware("admin"), metricsController.GetClickPipelineStats)
+	}
+}
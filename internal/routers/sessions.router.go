@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"koda-shortlink/internal/handler"
+	"koda-shortlink/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func SessionRoutes(r *gin.Engine, pg *pgxpool.Pool) {
+	sessionController := handler.SessionController{DB: pg}
+
+	sessions := r.Group("/api/v1/sessions")
+	sessions.Use(middleware.AuthMiddleware(""))
+	{
+		sessions.GET("", sessionController.GetSessions)
+		sessions.DELETE("/others", sessionController.RevokeOtherSessions)
+		sessions.DELETE("/:id", sessionController.RevokeSession)
+	}
+}
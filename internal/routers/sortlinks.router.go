@@ -3,6 +3,7 @@ package routers
 import (
 	"koda-shortlink/internal/handler"
 	"koda-shortlink/internal/middleware"
+	"koda-shortlink/internal/models"
 
 	"time"
 
@@ -13,18 +14,35 @@ import (
 func ShortlinkRoutes(r *gin.Engine, pg *pgxpool.Pool) {
 	shortlinkController := handler.ShortlinkController{DB: pg}
 
+	// Personal API keys are accepted next to JWTs on these routes, limited
+	// to the key's scopes.
+	linksRead := middleware.APIKeyMiddleware(pg, models.APIKeyScopeLinksRead)
+	linksWrite := middleware.APIKeyMiddleware(pg, models.APIKeyScopeLinksWrite)
+	statsRead := middleware.APIKeyMiddleware(pg, models.APIKeyScopeStatsRead)
+
 	shortlinks := r.Group("/api/v1")
 	opt := shortlinks.Group(("/"))
-	opt.Use(middleware.OptAuthMiddleware(""))
+	opt.Use(linksWrite, middleware.OptAuthMiddleware(""))
 	opt.POST("/links",middleware.RateLimitMiddleware(5, 5*time.Minute) ,shortlinkController.CreateShortlink)
+	opt.POST("/links/bulk", middleware.RateLimitMiddleware(5, 5*time.Minute), shortlinkController.CreateShortlinksBulk)
 	{
-		shortlinks.GET("/links", middleware.AuthMiddleware(""),shortlinkController.GetAllShortlinks)
-		shortlinks.GET("/links/:shortCode", middleware.AuthMiddleware(""),shortlinkController.GetShortlinkByCode)
-		shortlinks.PUT("/links/:shortCode",middleware.AuthMiddleware("") ,shortlinkController.UpdateShortlink)
-		shortlinks.DELETE("/links/:shortCode", middleware.AuthMiddleware(""),shortlinkController.DeleteShortlink)
-		shortlinks.GET("/dashboard/stats", middleware.AuthMiddleware(""),shortlinkController.GetDashboardStats )
+		shortlinks.GET("/links", linksRead, middleware.AuthMiddleware(""),shortlinkController.GetAllShortlinks)
+		shortlinks.GET("/links/:shortCode", linksRead, middleware.AuthMiddleware(""),shortlinkController.GetShortlinkByCode)
+		shortlinks.PUT("/links/:shortCode",linksWrite, middleware.AuthMiddleware("") ,shortlinkController.UpdateShortlink)
+		shortlinks.DELETE("/links/:shortCode", linksWrite, middleware.AuthMiddleware(""),shortlinkController.DeleteShortlink)
+		shortlinks.PUT("/links/:shortCode/password", linksWrite, middleware.AuthMiddleware(""), shortlinkController.SetShortlinkPassword)
+		shortlinks.DELETE("/links/:shortCode/password", linksWrite, middleware.AuthMiddleware(""), shortlinkController.RemoveShortlinkPassword)
+		shortlinks.GET("/links/:shortCode/stats", statsRead, middleware.AuthMiddleware(""), shortlinkController.GetShortlinkStats)
+		shortlinks.GET("/links/:shortCode/viewers", statsRead, middleware.AuthMiddleware(""), shortlinkController.GetShortlinkViewers)
+		shortlinks.GET("/dashboard/stats", statsRead, middleware.AuthMiddleware(""),shortlinkController.GetDashboardStats )
+		shortlinks.GET("/export/links", linksRead, middleware.AuthMiddleware(""), shortlinkController.ExportShortlinks)
+		shortlinks.GET("/export/clicks", statsRead, middleware.AuthMiddleware(""), shortlinkController.ExportClicks)
+		shortlinks.POST("/stream/ticket", statsRead, middleware.AuthMiddleware(""), shortlinkController.CreateStreamTicket)
+		shortlinks.GET("/stream/clicks", middleware.StreamTicketMiddleware(), statsRead, middleware.AuthMiddleware(""), shortlinkController.StreamClicks)
 	}
 	
-	r.GET("/:shortCode", shortlinkController.GetShortlinksRedis)
+	r.GET("/:shortCode", middleware.ViewerAuthMiddleware(), shortlinkController.GetShortlinksRedis)
+	r.HEAD("/:shortCode", middleware.ViewerAuthMiddleware(), shortlinkController.GetShortlinksRedis)
+	r.POST("/:shortCode/unlock", shortlinkController.UnlockShortlink)
 
 }
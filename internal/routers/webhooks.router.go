@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"koda-shortlink/internal/handler"
+	"koda-shortlink/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func WebhookRoutes(r *gin.Engine, pg *pgxpool.Pool) {
+	webhookController := handler.WebhookController{DB: pg}
+
+	webhooks := r.Group("/api/v1/webhooks")
+	webhooks.Use(middleware.AuthMiddleware(""))
+	{
+		webhooks.POST("", webhookController.CreateWebhook)
+		webhooks.GET("", webhookController.GetWebhooks)
+		webhooks.GET("/:id", webhookController.GetWebhook)
+		webhooks.PUT("/:id", webhookController.UpdateWebhook)
+		webhooks.DELETE("/:id", webhookController.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", webhookController.GetWebhookDeliveries)
+		webhooks.POST("/:id/deliveries/:deliveryId/replay", webhookController.ReplayWebhookDelivery)
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+)
+
+// Dashboard responses are cached per requested range. Rather than tracking
+// every cached range, each key embeds a per-user version that
+// InvalidateDashboardCache bumps, orphaning the old entries until they expire.
+
+func dashboardVersionKey(userID int64) string {
+	return fmt.Sprintf("analytics:user:%d:version", userID)
+}
+
+func DashboardCacheKey(ctx context.Context, userID int64, rangeKey string) string {
+	version, _ := RedisClient.Get(ctx, dashboardVersionKey(userID)).Int64()
+	return fmt.Sprintf("analytics:user:%d:v%d:%s", userID, version, rangeKey)
+}
+
+func InvalidateDashboardCache(ctx context.Context, userIDs ...int64) {
+	if len(userIDs) == 0 {
+		return
+	}
+	pipe := RedisClient.Pipeline()
+	for _, userID := range userIDs {
+		pipe.Incr(ctx, dashboardVersionKey(userID))
+	}
+	pipe.Exec(ctx)
+}
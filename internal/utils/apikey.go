@@ -0,0 +1,19 @@
+package utils
+
+// APIKeyPrefix starts every personal API key, which tells the middleware
+// apart from a JWT.
+const APIKeyPrefix = "sk_"
+
+// apiKeyHintLen is how much of a key is kept in clear so users can tell
+// their keys apart.
+const apiKeyHintLen = 8
+
+// GenerateAPIKey returns a new key and the prefix of it that may be shown
+// again later.
+func GenerateAPIKey() (key, hint string, err error) {
+	secret, err := RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return APIKeyPrefix + secret, APIKeyPrefix + secret[:apiKeyHintLen], nil
+}
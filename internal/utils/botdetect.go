@@ -0,0 +1,49 @@
+package utils
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+
+	"github.com/mileusna/useragent"
+)
+
+//go:embed botsignatures.txt
+var botSignaturesFile string
+
+var botSignatures = parseBotSignatures(botSignaturesFile)
+
+func parseBotSignatures(list string) []string {
+	var signatures []string
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		signatures = append(signatures, line)
+	}
+	return signatures
+}
+
+// IsBotRequest classifies a request as automated. HEAD requests and a missing
+// User-Agent are treated as bots, as are agents matching botsignatures.txt or
+// flagged by the user agent parser.
+func IsBotRequest(method, userAgent string) bool {
+	if method == http.MethodHead {
+		return true
+	}
+
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return true
+	}
+
+	lower := strings.ToLower(userAgent)
+	for _, signature := range botSignatures {
+		if strings.Contains(lower, signature) {
+			return true
+		}
+	}
+
+	return useragent.Parse(userAgent).Bot
+}
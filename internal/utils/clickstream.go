@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type ClickStreamEvent struct {
+	ShortCode   string    `json:"shortCode"`
+	ClickedAt   time.Time `json:"clickedAt"`
+	CountryCode string    `json:"countryCode,omitempty"`
+	Device      string    `json:"device,omitempty"`
+	Bot         bool      `json:"bot"`
+	Total       int64     `json:"total"`
+}
+
+func ClickStreamChannel(userID int64) string {
+	return fmt.Sprintf("stream:user:%d:clicks", userID)
+}
+
+// PublishClicks fans clicks, keyed by owner, out to every instance holding a
+// stream open for the owner, in one round trip.
+func PublishClicks(ctx context.Context, events map[int64][]ClickStreamEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	pipe := RedisClient.Pipeline()
+	for ownerID, owned := range events {
+		for _, event := range owned {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			pipe.Publish(ctx, ClickStreamChannel(ownerID), payload)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// StreamTicketTTL is how long a stream ticket can wait to be used.
+const StreamTicketTTL = 30 * time.Second
+
+func streamTicketKey(ticket string) string {
+	return "stream:ticket:" + HashToken(ticket)
+}
+
+// NewStreamTicket stores the bearer credential of an authenticated request
+// under a random single-use ticket. Browsers' EventSource cannot send
+// headers, and a ticket in the URL is harmless once access logs see it.
+func NewStreamTicket(ctx context.Context, credential string) (string, error) {
+	ticket, err := RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := RedisClient.Set(ctx, streamTicketKey(ticket), credential, StreamTicketTTL).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// RedeemStreamTicket returns the credential stored under ticket and deletes
+// it, or redis.Nil when the ticket is unknown, used or expired.
+func RedeemStreamTicket(ctx context.Context, ticket string) (string, error) {
+	return RedisClient.GetDel(ctx, streamTicketKey(ticket)).Result()
+}
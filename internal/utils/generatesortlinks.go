@@ -1,16 +1,190 @@
 package utils
 
 import (
-    "math/rand"
-    "time"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
 )
 
+const (
+	DefaultShortCodeAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	DefaultShortCodeLength   = 6
+
+	ShortCodeStrategyRandom   = "random"
+	ShortCodeStrategySequence = "sequence"
+	ShortCodeStrategyHash     = "hash"
+)
+
+// ShortCodeSource carries everything a generator may derive a code from.
+// Seq is a fresh value of the shortlinks id sequence and Attempt counts
+// retries after a collision, starting at 0.
+type ShortCodeSource struct {
+	Seq     int64
+	URL     string
+	Attempt int
+}
+
+type ShortCodeGenerator interface {
+	Generate(src ShortCodeSource) (string, error)
+}
+
+var ShortCodeGen ShortCodeGenerator = &RandomShortCodeGenerator{
+	Length:   DefaultShortCodeLength,
+	Alphabet: DefaultShortCodeAlphabet,
+}
+
+// InitShortCodeGenerator picks the strategy configured through
+// SHORTCODE_STRATEGY, SHORTCODE_LENGTH and SHORTCODE_ALPHABET.
+func InitShortCodeGenerator() {
+	length := DefaultShortCodeLength
+	if v := os.Getenv("SHORTCODE_LENGTH"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < MinShortCodeLength || n > MaxShortCodeLength {
+			log.Printf("invalid SHORTCODE_LENGTH %q, using %d", v, DefaultShortCodeLength)
+		} else {
+			length = n
+		}
+	}
+
+	alphabet := DefaultShortCodeAlphabet
+	if v := os.Getenv("SHORTCODE_ALPHABET"); v != "" {
+		if err := validateAlphabet(v); err != nil {
+			log.Printf("invalid SHORTCODE_ALPHABET: %v, using default", err)
+		} else {
+			alphabet = v
+		}
+	}
+
+	gen, err := NewShortCodeGenerator(os.Getenv("SHORTCODE_STRATEGY"), length, alphabet)
+	if err != nil {
+		log.Printf("%v, using %s", err, ShortCodeStrategyRandom)
+		gen, _ = NewShortCodeGenerator(ShortCodeStrategyRandom, length, alphabet)
+	}
+
+	ShortCodeGen = gen
+}
+
+func NewShortCodeGenerator(strategy string, length int, alphabet string) (ShortCodeGenerator, error) {
+	switch strategy {
+	case "", ShortCodeStrategyRandom:
+		return &RandomShortCodeGenerator{Length: length, Alphabet: alphabet}, nil
+	case ShortCodeStrategySequence:
+		return &SequenceShortCodeGenerator{Length: length, Alphabet: alphabet}, nil
+	case ShortCodeStrategyHash:
+		return &HashShortCodeGenerator{Length: length, Alphabet: alphabet}, nil
+	default:
+		return nil, errors.New("unknown short code strategy " + strconv.Quote(strategy))
+	}
+}
+
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return errors.New("alphabet needs at least 2 characters")
+	}
+	if !IsValidShortCode(alphabet) {
+		return errors.New("alphabet must be alphanumeric")
+	}
+	seen := map[rune]bool{}
+	for _, r := range alphabet {
+		if seen[r] {
+			return errors.New("alphabet contains duplicate characters")
+		}
+		seen[r] = true
+	}
+	return nil
+}
+
+// RandomShortCodeGenerator draws every character from crypto/rand.
+type RandomShortCodeGenerator struct {
+	Length   int
+	Alphabet string
+}
+
+func (g *RandomShortCodeGenerator) Generate(_ ShortCodeSource) (string, error) {
+	max := big.NewInt(int64(len(g.Alphabet)))
+	b := make([]byte, g.Length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = g.Alphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// SequenceShortCodeGenerator encodes the sequence id in the alphabet's base,
+// left-padded to Length. Codes are unique as long as the sequence is.
+type SequenceShortCodeGenerator struct {
+	Length   int
+	Alphabet string
+}
+
+func (g *SequenceShortCodeGenerator) Generate(src ShortCodeSource) (string, error) {
+	if src.Seq <= 0 {
+		return "", errors.New("sequence strategy needs a sequence id")
+	}
+
+	code := encodeBase(big.NewInt(src.Seq), g.Alphabet)
+	if len(code) > MaxShortCodeLength {
+		return "", errors.New("sequence id does not fit in a short code")
+	}
+	if len(code) < g.Length {
+		code = strings.Repeat(g.Alphabet[:1], g.Length-len(code)) + code
+	}
+	return code, nil
+}
+
+// HashShortCodeGenerator derives the code from a SHA-256 of the URL, so the
+// same URL maps to the same code. Retries salt the hash with the sequence id
+// so a URL that was already shortened still gets a code on the next attempt.
+type HashShortCodeGenerator struct {
+	Length   int
+	Alphabet string
+}
+
+func (g *HashShortCodeGenerator) Generate(src ShortCodeSource) (string, error) {
+	input := src.URL
+	if src.Attempt > 0 {
+		input += "#" + strconv.FormatInt(src.Seq, 10)
+	}
+	sum := sha256.Sum256([]byte(input))
+
+	code := encodeBase(new(big.Int).SetBytes(sum[:]), g.Alphabet)
+	if len(code) > g.Length {
+		code = code[:g.Length]
+	}
+	return code, nil
+}
+
+func encodeBase(n *big.Int, alphabet string) string {
+	base := big.NewInt(int64(len(alphabet)))
+	if n.Sign() == 0 {
+		return alphabet[:1]
+	}
+
+	n = new(big.Int).Set(n)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
 func GenerateShortCode(n int) string {
-    const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-    rand.Seed(time.Now().UnixNano())
-    b := make([]byte, n)
-    for i := range b {
-        b[i] = letters[rand.Intn(len(letters))]
-    }
-    return string(b)
+	code, err := (&RandomShortCodeGenerator{Length: n, Alphabet: DefaultShortCodeAlphabet}).Generate(ShortCodeSource{})
+	if err != nil {
+		log.Println("GenerateShortCode:", err)
+	}
+	return code
 }
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"log"
+	"net"
+	"os"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+var GeoIPReader *maxminddb.Reader
+
+type GeoInfo struct {
+	CountryCode string
+	RegionCode  string
+	City        string
+}
+
+type geoRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// InitGeoIP opens the MaxMind-format database at GEOIP_DB_PATH. Without one
+// lookups return empty results and clicks are stored without geography.
+func InitGeoIP() {
+	path := os.Getenv("GEOIP_DB_PATH")
+	if path == "" {
+		log.Println("GEOIP_DB_PATH is not set, GeoIP lookups disabled")
+		return
+	}
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		log.Printf("Failed to open GeoIP database, GeoIP lookups disabled: %v", err)
+		return
+	}
+
+	GeoIPReader = reader
+}
+
+// LookupGeo works with both Country and City databases; fields the database
+// doesn't carry are left empty.
+func LookupGeo(ip string) GeoInfo {
+	if GeoIPReader == nil {
+		return GeoInfo{}
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return GeoInfo{}
+	}
+
+	var record geoRecord
+	if err := GeoIPReader.Lookup(parsed, &record); err != nil {
+		return GeoInfo{}
+	}
+
+	info := GeoInfo{
+		CountryCode: record.Country.ISOCode,
+		City:        record.City.Names["en"],
+	}
+	if len(record.Subdivisions) > 0 && record.Subdivisions[0].ISOCode != "" {
+		info.RegionCode = record.Country.ISOCode + "-" + record.Subdivisions[0].ISOCode
+	}
+
+	return info
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Daily HyperLogLogs are kept a little over a year; the all-time ones never
+// expire.
+const uniqueVisitRetention = 400 * 24 * time.Hour
+
+func VisitorFingerprint(ip, userAgent string) string {
+	sum := sha256.Sum256([]byte(ip + "\x00" + userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+func LinkUniqueKey(shortlinkID int, day time.Time) string {
+	return fmt.Sprintf("hll:link:%d:%s", shortlinkID, day.UTC().Format("2006-01-02"))
+}
+
+func LinkUniqueAllKey(shortlinkID int) string {
+	return fmt.Sprintf("hll:link:%d:all", shortlinkID)
+}
+
+func UserUniqueKey(userID int64, day time.Time) string {
+	return fmt.Sprintf("hll:user:%d:%s", userID, day.UTC().Format("2006-01-02"))
+}
+
+func UserUniqueAllKey(userID int64) string {
+	return fmt.Sprintf("hll:user:%d:all", userID)
+}
+
+func GlobalUniqueKey(day time.Time) string {
+	return "hll:global:" + day.UTC().Format("2006-01-02")
+}
+
+func GlobalUniqueAllKey() string {
+	return "hll:global:all"
+}
+
+// RecordUniqueVisit adds the visitor to the link's, the owner's and the
+// global daily and all-time HyperLogLogs.
+func RecordUniqueVisit(ctx context.Context, shortlinkID int, ownerID *int64, fingerprint string, at time.Time) error {
+	pipe := RedisClient.Pipeline()
+
+	dayKey := LinkUniqueKey(shortlinkID, at)
+	pipe.PFAdd(ctx, dayKey, fingerprint)
+	pipe.Expire(ctx, dayKey, uniqueVisitRetention)
+	pipe.PFAdd(ctx, LinkUniqueAllKey(shortlinkID), fingerprint)
+
+	globalDayKey := GlobalUniqueKey(at)
+	pipe.PFAdd(ctx, globalDayKey, fingerprint)
+	pipe.Expire(ctx, globalDayKey, uniqueVisitRetention)
+	pipe.PFAdd(ctx, GlobalUniqueAllKey(), fingerprint)
+
+	if ownerID != nil {
+		userDayKey := UserUniqueKey(*ownerID, at)
+		pipe.PFAdd(ctx, userDayKey, fingerprint)
+		pipe.Expire(ctx, userDayKey, uniqueVisitRetention)
+		pipe.PFAdd(ctx, UserUniqueAllKey(*ownerID), fingerprint)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// LinkUniqueKeysBetween lists the link's daily keys covering [from, to),
+// limited to the retention window.
+func LinkUniqueKeysBetween(shortlinkID int, from, to time.Time) []string {
+	return uniqueKeysBetween(from, to, func(day time.Time) string { return LinkUniqueKey(shortlinkID, day) })
+}
+
+func UserUniqueKeysBetween(userID int64, from, to time.Time) []string {
+	return uniqueKeysBetween(from, to, func(day time.Time) string { return UserUniqueKey(userID, day) })
+}
+
+func uniqueKeysBetween(from, to time.Time, key func(time.Time) string) []string {
+	if oldest := time.Now().Add(-uniqueVisitRetention); from.Before(oldest) {
+		from = oldest
+	}
+
+	var keys []string
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.Add(24 * time.Hour) {
+		keys = append(keys, key(day))
+	}
+	return keys
+}
+
+// CountUniqueVisits estimates the visitors in the union of keys.
+func CountUniqueVisits(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	return RedisClient.PFCount(ctx, keys...).Result()
+}
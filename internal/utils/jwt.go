@@ -14,19 +14,38 @@ type UserPayload struct {
 	Id    int    `json:"id"`
 	Email string `json:"email"`
 	Role  string `json:"role"`
+	// SessionID is the token family the access token was issued for.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(id int, email, role string) (string, error) {
+// Issue times are kept to the millisecond so a revocation cutoff set by
+// RevokeUserTokens catches tokens issued earlier in the same second.
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
+// AccessTokenTTL is how long an access token is valid.
+const AccessTokenTTL = 15 * time.Minute
+
+// GenerateToken issues an access token. Its random ID lets it be revoked on
+// its own before it expires.
+func GenerateToken(id int, email, role, sessionID string) (string, error) {
 	secretKey := os.Getenv("JWT_SECRET")
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
 	claims := &UserPayload{
-		Id:    id,
-		Email: email,
-		Role:  role,
+		Id:        id,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "kodashortlink",
+			ID:        jti,
 		},
 	}
 
@@ -34,8 +53,37 @@ func GenerateToken(id int, email, role string) (string, error) {
 	return token.SignedString([]byte(secretKey))
 }
 
+// ParseAccessToken returns the claims of a valid access token. It doesn't
+// check revocation.
+func ParseAccessToken(tokenStr string) (*UserPayload, error) {
+	secretKey := os.Getenv("JWT_SECRET")
+	claims := &UserPayload{}
+
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrInvalidKeyType
+		}
+		return []byte(secretKey), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return claims, nil
+}
+
+// GenerateRefreshToken gives every token a random ID, so two tokens issued
+// in the same second still differ.
 func GenerateRefreshToken(id int, email, role string) (string, error) {
 	secretKey := os.Getenv("JWT_REFRESH_SECRET")
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
 	claims := &UserPayload{
 		Id:    id,
 		Email: email,
@@ -44,6 +92,7 @@ func GenerateRefreshToken(id int, email, role string) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), 
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "kodashortlink",
+			ID:        jti,
 		},
 	}
 
@@ -69,6 +118,104 @@ func VerifyRefreshToken(tokenStr string) (*UserPayload, error) {
 	return claims, nil
 }
 
+type UnlockPayload struct {
+	ShortCode string `json:"code"`
+	jwt.RegisteredClaims
+}
+
+// unlockSecret never equals JWT_SECRET so an unlock token can't pass as an
+// access token.
+func unlockSecret() string {
+	if secret := os.Getenv("LINK_UNLOCK_SECRET"); secret != "" {
+		return secret
+	}
+	return os.Getenv("JWT_SECRET") + ":unlock"
+}
+
+// GenerateUnlockToken proves the holder entered the password of a protected
+// shortlink. It is only valid for that short code.
+func GenerateUnlockToken(shortCode string, ttl time.Duration) (string, error) {
+	secretKey := unlockSecret()
+	claims := &UnlockPayload{
+		ShortCode: shortCode,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "kodashortlink",
+			Audience:  jwt.ClaimStrings{"unlock"},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secretKey))
+}
+
+func VerifyUnlockToken(tokenStr, shortCode string) bool {
+	secretKey := unlockSecret()
+	claims := &UnlockPayload{}
+
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrInvalidKeyType
+		}
+		return []byte(secretKey), nil
+	}, jwt.WithAudience("unlock"))
+
+	return err == nil && token.Valid && claims.ShortCode == shortCode
+}
+
+type EmailVerificationPayload struct {
+	Id    int64  `json:"id"`
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+func emailVerificationSecret() string {
+	if secret := os.Getenv("EMAIL_VERIFICATION_SECRET"); secret != "" {
+		return secret
+	}
+	return os.Getenv("JWT_SECRET") + ":verify_email"
+}
+
+// GenerateEmailVerificationToken signs the address being verified, so the
+// token is worthless once the user changes their email.
+func GenerateEmailVerificationToken(id int64, email string, ttl time.Duration) (string, error) {
+	secretKey := emailVerificationSecret()
+	claims := &EmailVerificationPayload{
+		Id:    id,
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "kodashortlink",
+			Audience:  jwt.ClaimStrings{"verify_email"},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secretKey))
+}
+
+func VerifyEmailVerificationToken(tokenStr string) (*EmailVerificationPayload, error) {
+	secretKey := emailVerificationSecret()
+	claims := &EmailVerificationPayload{}
+
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrInvalidKeyType
+		}
+		return []byte(secretKey), nil
+	}, jwt.WithAudience("verify_email"))
+
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return claims, nil
+}
 
 func JWTMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends transactional mail. SMTPMailer delivers it; OutboxMailer
+// keeps it on disk or in the log for development and tests.
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+// Mail is set by InitMailer. It defaults to an outbox that only logs who a
+// message went to.
+var Mail Mailer = &OutboxMailer{}
+
+// InitMailer picks the mailer from MAIL_DRIVER: smtp (SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD), outbox (MAIL_OUTBOX_DIR) or log. Only log
+// writes whole messages, with their reset and verification links, to the
+// process log; without a driver or outbox directory mail is dropped and just
+// the recipient and subject are logged. MAIL_FROM is the sender for all.
+func InitMailer() {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		Mail = &SMTPMailer{
+			Addr:     net.JoinHostPort(os.Getenv("SMTP_HOST"), port),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	case "log":
+		Mail = &OutboxMailer{From: from, LogBodies: true}
+	default:
+		dir := os.Getenv("MAIL_OUTBOX_DIR")
+		if dir == "" {
+			log.Println("mail: no MAIL_DRIVER or MAIL_OUTBOX_DIR configured, mail will not be delivered")
+		}
+		Mail = &OutboxMailer{Dir: dir, From: from}
+	}
+}
+
+// SendMailAsync sends msg in the background so a request's response time
+// doesn't tell whether mail was sent.
+func SendMailAsync(msg MailMessage) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := Mail.Send(ctx, msg); err != nil {
+			log.Printf("mail to %s: %v", msg.To, err)
+		}
+	}()
+}
+
+func formatMail(from string, msg MailMessage) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+type SMTPMailer struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send uses STARTTLS when the server offers it, which net/smtp does on its
+// own; PLAIN auth is only attempted when a username is configured.
+func (m *SMTPMailer) Send(ctx context.Context, msg MailMessage) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, _ := net.SplitHostPort(m.Addr)
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.Addr, auth, m.From, []string{msg.To}, formatMail(m.From, msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// outboxKeep bounds how many messages an OutboxMailer remembers.
+const outboxKeep = 100
+
+// OutboxMailer writes every message as an .eml file to Dir. When Dir is
+// empty it logs the whole message if LogBodies is set, and otherwise only the
+// recipient and subject, since bodies carry live tokens. The latest messages
+// are also kept in memory so tests can read them back with Messages.
+type OutboxMailer struct {
+	Dir       string
+	From      string
+	LogBodies bool
+
+	mu   sync.Mutex
+	sent []MailMessage
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg MailMessage) error {
+	m.mu.Lock()
+	m.sent = append(m.sent, msg)
+	if len(m.sent) > outboxKeep {
+		m.sent = m.sent[len(m.sent)-outboxKeep:]
+	}
+	m.mu.Unlock()
+
+	raw := formatMail(m.From, msg)
+	if m.Dir == "" {
+		if m.LogBodies {
+			log.Printf("mail outbox:\n%s", raw)
+		} else {
+			log.Printf("mail outbox: %q to %s not delivered", msg.Subject, msg.To)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), sanitizeMailName(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), raw, 0o600)
+}
+
+// Messages returns a copy of the latest messages sent.
+func (m *OutboxMailer) Messages() []MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MailMessage(nil), m.sent...)
+}
+
+func sanitizeMailName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, s)
+}
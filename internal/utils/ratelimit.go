@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimitHit counts one hit against key and starts the window on the
+// first hit. It returns the number of hits in the current window.
+func RateLimitHit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	count, err := RedisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 {
+		RedisClient.Expire(ctx, key, window)
+	}
+
+	return count, nil
+}
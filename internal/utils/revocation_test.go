@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestIssuedBefore(t *testing.T) {
+	cutoff := time.Date(2026, 1, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+
+	tests := []struct {
+		name string
+		iat  *jwt.NumericDate
+		want bool
+	}{
+		{"no issue time", nil, true},
+		{"previous second", jwt.NewNumericDate(cutoff.Add(-time.Second)), true},
+		{"earlier in the same second", jwt.NewNumericDate(cutoff.Add(-time.Millisecond)), true},
+		{"same millisecond", jwt.NewNumericDate(cutoff), false},
+		{"later in the same second", jwt.NewNumericDate(cutoff.Add(time.Millisecond)), false},
+		{"next second", jwt.NewNumericDate(cutoff.Add(time.Second)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := issuedBefore(tt.iat, cutoff.UnixMicro()); got != tt.want {
+				t.Errorf("issuedBefore() = %v, want %v", got, tt.want)
+			}
+			if tt.iat == nil {
+				return
+			}
+
+			// The issue time has to survive being signed into a token.
+			raw, err := json.Marshal(tt.iat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded jwt.NumericDate
+			if err := json.Unmarshal(raw, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if got := issuedBefore(&decoded, cutoff.UnixMicro()); got != tt.want {
+				t.Errorf("issuedBefore(%s) = %v, want %v", raw, got, tt.want)
+			}
+		})
+	}
+}
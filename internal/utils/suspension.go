@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+)
+
+// Suspensions are mirrored in Redis so the auth middleware can turn away a
+// suspended user's access tokens without a database lookup per request.
+
+func suspendedUserKey(userID int64) string {
+	return fmt.Sprintf("auth:user:%d:suspended", userID)
+}
+
+func MarkUserSuspended(ctx context.Context, userID int64, suspended bool) error {
+	if suspended {
+		return RedisClient.Set(ctx, suspendedUserKey(userID), 1, 0).Err()
+	}
+	return RedisClient.Del(ctx, suspendedUserKey(userID)).Err()
+}
+
+// IsUserSuspended reports false when Redis can't be reached; login and token
+// refresh check the database, so a suspended user is locked out once their
+// access token expires.
+func IsUserSuspended(ctx context.Context, userID int64) bool {
+	n, err := RedisClient.Exists(ctx, suspendedUserKey(userID)).Result()
+	return err == nil && n > 0
+}
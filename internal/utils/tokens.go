@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// RandomToken returns n random bytes, URL-safe base64 encoded.
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// RandomDigits returns a numeric code of n digits, e.g. an OTP.
+func RandomDigits(n int) (string, error) {
+	code := make([]byte, n)
+	for i := range code {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + d.Int64())
+	}
+	return string(code), nil
+}
+
+// HashToken is how one-time secrets are stored: they are random enough that
+// an unsalted SHA-256 can't be reversed, and it can be looked up directly.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashScopedToken hashes a short secret such as an OTP together with what it
+// belongs to, so equal codes of different users don't hash the same.
+func HashScopedToken(scope, token string) string {
+	return HashToken(fmt.Sprintf("%s:%s", scope, token))
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/mileusna/useragent"
+)
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceUnknown = "unknown"
+)
+
+type ClientInfo struct {
+	Browser string
+	OS      string
+	Device  string
+}
+
+func ParseUserAgent(userAgent string) ClientInfo {
+	if userAgent == "" {
+		return ClientInfo{Device: DeviceUnknown}
+	}
+
+	ua := useragent.Parse(userAgent)
+	info := ClientInfo{Browser: ua.Name, OS: ua.OS, Device: DeviceUnknown}
+
+	switch {
+	case ua.Bot:
+		info.Device = DeviceBot
+	case ua.Tablet:
+		info.Device = DeviceTablet
+	case ua.Mobile:
+		info.Device = DeviceMobile
+	case ua.Desktop:
+		info.Device = DeviceDesktop
+	}
+
+	return info
+}
+
+// ReferrerDomain returns the lower-cased host of a Referer header without a
+// leading "www.", or "" when there is none.
+func ReferrerDomain(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(referrer)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
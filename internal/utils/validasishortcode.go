@@ -1,8 +1,41 @@
 package utils
 
-import "regexp"
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	MinShortCodeLength = 3
+	MaxShortCodeLength = 10
+)
+
+var shortCodeRegex = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+
+var reservedShortCodes = map[string]bool{
+	"api":       true,
+	"admin":     true,
+	"auth":      true,
+	"login":     true,
+	"logout":    true,
+	"register":  true,
+	"dashboard": true,
+	"profile":   true,
+	"links":     true,
+	"swagger":   true,
+	"uploads":   true,
+	"static":    true,
+	"health":    true,
+}
 
 func IsValidShortCode(code string) bool {
-	regex := regexp.MustCompile(`^[a-zA-Z0-9]+$`)
-	return regex.MatchString(code)
+	return shortCodeRegex.MatchString(code)
+}
+
+func IsValidShortCodeLength(code string) bool {
+	return len(code) >= MinShortCodeLength && len(code) <= MaxShortCodeLength
+}
+
+func IsReservedShortCode(code string) bool {
+	return reservedShortCodes[strings.ToLower(code)]
 }
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Webhook receivers are given by users, so the server must not be made to
+// call itself or anything else on its private network. Targets are checked
+// when a webhook is saved, and every connection the dispatcher opens is
+// checked again on the address actually dialed, so a DNS answer that changes
+// in between (DNS rebinding) doesn't get around it.
+
+var ErrWebhookTargetNotAllowed = errors.New("webhook target resolves to a non-public address")
+
+// cgnat is the carrier-grade NAT range, not covered by net.IP.IsPrivate.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// webhookPrivateTargetsAllowed lets local development point webhooks at
+// localhost with WEBHOOK_ALLOW_PRIVATE_TARGETS=true.
+func webhookPrivateTargetsAllowed() bool {
+	return os.Getenv("WEBHOOK_ALLOW_PRIVATE_TARGETS") == "true"
+}
+
+// IsPublicIP reports whether ip is a routable unicast address, i.e. not
+// loopback, private, link-local, multicast or unspecified.
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnat.Contains(ip))
+}
+
+// CheckWebhookURL resolves the host of rawURL and fails unless it is an
+// http(s) URL whose every address is public.
+func CheckWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("missing host")
+	}
+	if webhookPrivateTargetsAllowed() {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return ErrWebhookTargetNotAllowed
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no addresses for %s", host)
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return ErrWebhookTargetNotAllowed
+		}
+	}
+	return nil
+}
+
+// webhookDialControl runs after DNS resolution, on the address about to be
+// connected to.
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	if webhookPrivateTargetsAllowed() {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return ErrWebhookTargetNotAllowed
+	}
+	return nil
+}
+
+// NewWebhookHTTPClient returns a client that only connects to public
+// addresses, ignores proxy settings and doesn't follow redirects.
+func NewWebhookHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: webhookDialControl,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          20,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
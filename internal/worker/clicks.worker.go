@@ -0,0 +1,383 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultClickQueueSize      = 10000
+	defaultClickBatchSize      = 500
+	defaultClickFlushInterval  = time.Second
+	defaultClickEnqueueTimeout = 50 * time.Millisecond
+	clickRetryAttempts         = 3
+	clickRetryBackoff          = 200 * time.Millisecond
+)
+
+// ClickEvent is everything the pipeline needs about one redirect. It is
+// captured in the handler, so nothing reads the gin context afterwards.
+type ClickEvent struct {
+	Click     models.ShortlinkClick
+	ShortCode string
+	OwnerID   *int64
+	// Counted is set when redirect_count was already incremented while
+	// enforcing the click budget; Total is then the count it reached.
+	// The pipeline fills in Total for the other clicks.
+	Counted bool
+	Total   int
+}
+
+type ClickPipelineStats struct {
+	QueueDepth    int   `json:"queueDepth"`
+	QueueCapacity int   `json:"queueCapacity"`
+	BatchSize     int   `json:"batchSize"`
+	Enqueued      int64 `json:"enqueued"`
+	Blocked       int64 `json:"blocked"`
+	Overflow      int64 `json:"overflow"`
+	Processed     int64 `json:"processed"`
+	Failed        int64 `json:"failed"`
+	Batches       int64 `json:"batches"`
+	LastBatchSize int64 `json:"lastBatchSize"`
+	LastFlushMs   int64 `json:"lastFlushMs"`
+}
+
+// ClickPipeline buffers clicks in a bounded channel and writes them in
+// batches. When the queue stays full for longer than the enqueue timeout the
+// click is written synchronously by the caller instead of being dropped.
+type ClickPipeline struct {
+	db             *pgxpool.Pool
+	queue          chan ClickEvent
+	batchSize      int
+	flushInterval  time.Duration
+	enqueueTimeout time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+
+	enqueued      atomic.Int64
+	blocked       atomic.Int64
+	overflow      atomic.Int64
+	processed     atomic.Int64
+	failed        atomic.Int64
+	batches       atomic.Int64
+	lastBatchSize atomic.Int64
+	lastFlushMs   atomic.Int64
+}
+
+// Clicks is nil until InitClickPipeline runs; RecordClick then writes each
+// click synchronously, which is what the serverless handler relies on.
+var Clicks *ClickPipeline
+
+// InitClickPipeline starts the click worker. CLICK_QUEUE_SIZE,
+// CLICK_BATCH_SIZE, CLICK_FLUSH_INTERVAL and CLICK_ENQUEUE_TIMEOUT tune it.
+func InitClickPipeline(db *pgxpool.Pool) {
+	Clicks = NewClickPipeline(db,
+		envInt("CLICK_QUEUE_SIZE", defaultClickQueueSize),
+		envInt("CLICK_BATCH_SIZE", defaultClickBatchSize),
+		envDuration("CLICK_FLUSH_INTERVAL", defaultClickFlushInterval),
+		envDuration("CLICK_ENQUEUE_TIMEOUT", defaultClickEnqueueTimeout),
+	)
+}
+
+func NewClickPipeline(db *pgxpool.Pool, queueSize, batchSize int, flushInterval, enqueueTimeout time.Duration) *ClickPipeline {
+	p := &ClickPipeline{
+		db:             db,
+		queue:          make(chan ClickEvent, queueSize),
+		batchSize:      batchSize,
+		flushInterval:  flushInterval,
+		enqueueTimeout: enqueueTimeout,
+		done:           make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// RecordClick hands the click to the pipeline, or stores it right away when
+// no pipeline is running.
+func RecordClick(db *pgxpool.Pool, event ClickEvent) {
+	if Clicks != nil && Clicks.Enqueue(event) {
+		return
+	}
+	if _, err := processClicks(db, []ClickEvent{event}); err != nil {
+		log.Printf("click pipeline: synchronous write failed: %v", err)
+	}
+}
+
+// Enqueue reports false when the click was not queued, either because the
+// pipeline is closed or because the queue stayed full for enqueueTimeout.
+func (p *ClickPipeline) Enqueue(event ClickEvent) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+
+	select {
+	case p.queue <- event:
+		p.enqueued.Add(1)
+		return true
+	default:
+	}
+
+	p.blocked.Add(1)
+	timer := time.NewTimer(p.enqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case p.queue <- event:
+		p.enqueued.Add(1)
+		return true
+	case <-timer.C:
+		p.overflow.Add(1)
+		return false
+	}
+}
+
+// Close stops accepting clicks and waits until the queue is drained or ctx
+// is done.
+func (p *ClickPipeline) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("click pipeline: %d clicks not flushed: %w", len(p.queue), ctx.Err())
+	}
+}
+
+func (p *ClickPipeline) Stats() ClickPipelineStats {
+	return ClickPipelineStats{
+		QueueDepth:    len(p.queue),
+		QueueCapacity: cap(p.queue),
+		BatchSize:     p.batchSize,
+		Enqueued:      p.enqueued.Load(),
+		Blocked:       p.blocked.Load(),
+		Overflow:      p.overflow.Load(),
+		Processed:     p.processed.Load(),
+		Failed:        p.failed.Load(),
+		Batches:       p.batches.Load(),
+		LastBatchSize: p.lastBatchSize.Load(),
+		LastFlushMs:   p.lastFlushMs.Load(),
+	}
+}
+
+func (p *ClickPipeline) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]ClickEvent, 0, p.batchSize)
+	for {
+		select {
+		case event, ok := <-p.queue:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= p.batchSize {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			p.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (p *ClickPipeline) flush(batch []ClickEvent) {
+	if len(batch) == 0 {
+		return
+	}
+
+	start := time.Now()
+	stored, err := processClicks(p.db, batch)
+	p.lastFlushMs.Store(time.Since(start).Milliseconds())
+	p.lastBatchSize.Store(int64(len(batch)))
+	p.batches.Add(1)
+
+	p.processed.Add(int64(stored))
+	if failed := len(batch) - stored; failed > 0 {
+		p.failed.Add(int64(failed))
+		log.Printf("click pipeline: %d of %d clicks not stored: %v", failed, len(batch), err)
+	}
+}
+
+// processClicks stores a batch, runs afterClicks on what was stored and
+// queues the webhook events. It returns how many clicks were stored.
+func processClicks(db *pgxpool.Pool, batch []ClickEvent) (int, error) {
+	stored, err := storeClicks(db, batch)
+	if len(stored) > 0 {
+		afterClicks(stored)
+	}
+	emitClickEvents(db, batch, stored)
+	return len(stored), err
+}
+
+// storeClicks writes the batch, retrying with backoff. A batch that fails on
+// its data is split in halves until the clicks that can't be stored are
+// isolated; those are dropped, but their redirects are still counted. It
+// returns the clicks that were stored.
+func storeClicks(db *pgxpool.Pool, batch []ClickEvent) ([]ClickEvent, error) {
+	err := saveClicksWithRetry(db, batch)
+	if err == nil {
+		return batch, nil
+	}
+	if !isClickDataError(err) {
+		return nil, err
+	}
+
+	if len(batch) == 1 {
+		id := batch[0].Click.ShortlinkID
+		log.Printf("click pipeline: dropping click on link %d: %v", id, err)
+		if !batch[0].Counted {
+			totals, err := models.AddRedirectCounts(db, map[int]int{id: 1})
+			if err != nil {
+				log.Printf("click pipeline: redirect count of link %d: %v", id, err)
+			}
+			batch[0].Total = totals[id]
+		}
+		return nil, err
+	}
+
+	mid := len(batch) / 2
+	left, errLeft := storeClicks(db, batch[:mid])
+	right, errRight := storeClicks(db, batch[mid:])
+	return append(left, right...), errors.Join(errLeft, errRight)
+}
+
+// saveClicksWithRetry retries failures that may be transient, such as a
+// lost connection; errors caused by the rows themselves are returned at once.
+func saveClicksWithRetry(db *pgxpool.Pool, batch []ClickEvent) error {
+	backoff := clickRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := saveClicks(db, batch)
+		if err == nil || isClickDataError(err) || attempt == clickRetryAttempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// isClickDataError reports errors caused by the rows written: data
+// exceptions (class 22) and integrity violations (class 23).
+func isClickDataError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+}
+
+func saveClicks(db *pgxpool.Pool, batch []ClickEvent) error {
+	clicks := make([]models.ShortlinkClick, len(batch))
+	redirects := make(map[int]int)
+	for i, event := range batch {
+		clicks[i] = event.Click
+		if !event.Counted {
+			redirects[event.Click.ShortlinkID]++
+		}
+	}
+
+	totals, err := models.SaveClickBatch(db, clicks, redirects)
+	if err != nil {
+		return err
+	}
+	assignTotals(batch, totals, redirects)
+	return nil
+}
+
+// assignTotals gives each click counted by the batch the redirect_count it
+// took its link to. totals are the counts after the whole batch, which the
+// link's last click in the batch reached.
+func assignTotals(batch []ClickEvent, totals, redirects map[int]int) {
+	seen := make(map[int]int)
+	for i := range batch {
+		id := batch[i].Click.ShortlinkID
+		total, ok := totals[id]
+		if batch[i].Counted || !ok {
+			continue
+		}
+		seen[id]++
+		batch[i].Total = total - redirects[id] + seen[id]
+	}
+}
+
+// afterClicks records unique visitors, announces the clicks on their
+// owners' click streams and drops the dashboard caches of every owner
+// touched, once per owner.
+func afterClicks(batch []ClickEvent) {
+	var owners []int64
+	streamed := make(map[int64][]utils.ClickStreamEvent)
+	for _, event := range batch {
+		if event.OwnerID == nil {
+			continue
+		}
+		if _, ok := streamed[*event.OwnerID]; !ok {
+			owners = append(owners, *event.OwnerID)
+		}
+		streamed[*event.OwnerID] = append(streamed[*event.OwnerID], utils.ClickStreamEvent{
+			ShortCode:   event.ShortCode,
+			ClickedAt:   event.Click.CreatedAt,
+			CountryCode: event.Click.CountryCode,
+			Device:      event.Click.Device,
+			Bot:         event.Click.IsBot,
+			Total:       int64(event.Total),
+		})
+	}
+
+	rctx := context.Background()
+	for _, event := range batch {
+		if event.Click.IsBot {
+			continue
+		}
+		fingerprint := utils.VisitorFingerprint(event.Click.IP, event.Click.UserAgent)
+		if err := utils.RecordUniqueVisit(rctx, event.Click.ShortlinkID, event.OwnerID, fingerprint, event.Click.CreatedAt); err != nil {
+			log.Printf("click pipeline: unique visit: %v", err)
+		}
+	}
+
+	if err := utils.PublishClicks(rctx, streamed); err != nil {
+		log.Printf("click pipeline: click stream: %v", err)
+	}
+
+	utils.RedisClient.Del(rctx, "analytics:global:7d")
+	utils.InvalidateDashboardCache(rctx, owners...)
+}
+
+func envInt(name string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"koda-shortlink/internal/models"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultRollupInterval = time.Hour
+	defaultRollupGrace    = 15 * time.Minute
+	rollupChunkDays       = 31
+)
+
+// InitDailyStatsAggregator keeps shortlink_daily_stats current. A day is
+// rolled up once ROLLUP_GRACE has passed since it ended, leaving the click
+// pipeline time to flush; ROLLUP_INTERVAL sets how often it checks.
+func InitDailyStatsAggregator(db *pgxpool.Pool) {
+	interval := envDuration("ROLLUP_INTERVAL", defaultRollupInterval)
+	grace := envDuration("ROLLUP_GRACE", defaultRollupGrace)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := RollupClosedDays(db, time.Now().Add(-grace)); err != nil {
+				log.Printf("daily stats rollup: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// RollupClosedDays rolls up every day from the watermark, or from the first
+// click before any rollup, up to the UTC day of until.
+func RollupClosedDays(db *pgxpool.Pool, until time.Time) error {
+	end := until.UTC().Truncate(24 * time.Hour)
+
+	start, err := models.GetRollupWatermark(db)
+	if err != nil {
+		return err
+	}
+	if start == nil {
+		if start, err = models.GetFirstClickDay(db); err != nil {
+			return err
+		}
+	}
+	if start == nil {
+		start = &end
+	}
+
+	return RollupRange(db, *start, end)
+}
+
+// RollupRange recomputes the days in [from, to) a month at a time, moving
+// the watermark after each chunk so progress survives a restart.
+func RollupRange(db *pgxpool.Pool, from, to time.Time) error {
+	if !from.Before(to) {
+		_, err := models.AdvanceRollupWatermark(db, from, to)
+		return err
+	}
+
+	for day := from; day.Before(to); {
+		next := day.AddDate(0, 0, rollupChunkDays)
+		if next.After(to) {
+			next = to
+		}
+
+		rows, err := models.RollupDays(db, day, next)
+		if err != nil {
+			return err
+		}
+		if _, err := models.AdvanceRollupWatermark(db, day, next); err != nil {
+			return err
+		}
+		log.Printf("daily stats rollup: %s to %s, %d rows", day.Format("2006-01-02"), next.Format("2006-01-02"), rows)
+
+		day = next
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"koda-shortlink/internal/models"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultClickPartitionsAhead = 3
+	clickPartitionCheckInterval = 24 * time.Hour
+)
+
+// InitClickPartitionManager creates the monthly shortlink_clicks partitions
+// ahead of time (CLICK_PARTITIONS_AHEAD months) and applies the retention
+// policy once a day.
+func InitClickPartitionManager(db *pgxpool.Pool) {
+	go func() {
+		ticker := time.NewTicker(clickPartitionCheckInterval)
+		defer ticker.Stop()
+		for {
+			if err := MaintainClickPartitions(db, time.Now()); err != nil {
+				log.Printf("click partitions: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// MaintainClickPartitions makes sure upcoming partitions exist, then retires
+// raw partitions older than CLICK_RETENTION_MONTHS full months (0 keeps
+// everything). CLICK_RETENTION_MODE is archive (default), which moves them to
+// the archive schema, or drop. A partition is only retired once every one of
+// its days is rolled up into shortlink_daily_stats.
+func MaintainClickPartitions(db *pgxpool.Pool, now time.Time) error {
+	now = now.UTC()
+	if err := models.EnsureClickPartitions(db, now, envInt("CLICK_PARTITIONS_AHEAD", defaultClickPartitionsAhead)); err != nil {
+		return err
+	}
+
+	retention := envInt("CLICK_RETENTION_MONTHS", 0)
+	if retention == 0 {
+		return nil
+	}
+	archive := os.Getenv("CLICK_RETENTION_MODE") != "drop"
+
+	watermark, err := models.GetRollupWatermark(db)
+	if err != nil || watermark == nil {
+		return err
+	}
+
+	cutoff := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -retention, 0)
+
+	partitions, err := models.ListClickPartitions(db)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range partitions {
+		if p.End().After(cutoff) || p.End().After(*watermark) {
+			continue
+		}
+		if err := models.RetireClickPartition(db, p, archive); err != nil {
+			return err
+		}
+		log.Printf("click partitions: retired %s (archive=%t)", p.Name, archive)
+	}
+	return nil
+}
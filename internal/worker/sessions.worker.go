@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"koda-shortlink/internal/models"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const defaultSessionPurgeInterval = time.Hour
+
+// InitSessionPurger deletes expired refresh tokens every
+// SESSION_PURGE_INTERVAL.
+func InitSessionPurger(db *pgxpool.Pool) {
+	interval := envDuration("SESSION_PURGE_INTERVAL", defaultSessionPurgeInterval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n, err := models.PurgeExpiredSessions(db)
+			if err != nil {
+				log.Printf("sessions: purge: %v", err)
+			} else if n > 0 {
+				log.Printf("sessions: purged %d expired refresh tokens", n)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultViewerClicksFlushInterval = time.Minute
+	viewerClicksScanCount            = 500
+	viewerClicksKeyPattern           = "viewers:link:*:user:*"
+)
+
+// InitViewerClickFlusher periodically moves the per-user redirect counters
+// from Redis into shortlink_viewer_clicks. VIEWER_CLICKS_FLUSH_INTERVAL sets
+// the period.
+func InitViewerClickFlusher(db *pgxpool.Pool) {
+	interval := envDuration("VIEWER_CLICKS_FLUSH_INTERVAL", defaultViewerClicksFlushInterval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := FlushViewerClicks(db); err != nil {
+				log.Printf("viewer clicks: %v", err)
+			}
+		}
+	}()
+}
+
+// FlushViewerClicks reads and resets every counter in one MULTI per key, so
+// increments landing during the flush are kept for the next run. If the
+// database write fails the counts are added back.
+func FlushViewerClicks(db *pgxpool.Pool) error {
+	ctx := context.Background()
+
+	var counts []models.ViewerClickCount
+	var cursor uint64
+	for {
+		keys, next, err := utils.RedisClient.Scan(ctx, cursor, viewerClicksKeyPattern, viewerClicksScanCount).Result()
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			shortlinkID, userID, ok := parseViewerClicksKey(key)
+			if !ok {
+				continue
+			}
+
+			clicks, err := takeCounter(ctx, key)
+			if err != nil {
+				return err
+			}
+			if clicks > 0 {
+				counts = append(counts, models.ViewerClickCount{ShortlinkID: shortlinkID, UserID: userID, Clicks: clicks})
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if err := models.AddViewerClicks(db, counts); err != nil {
+		for _, c := range counts {
+			utils.RedisClient.IncrBy(ctx, ViewerClicksKey(c.ShortlinkID, c.UserID), int64(c.Clicks))
+		}
+		return err
+	}
+	return nil
+}
+
+func takeCounter(ctx context.Context, key string) (int, error) {
+	var get *redis.StringCmd
+	_, err := utils.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+
+	n, err := get.Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
+
+// ViewerClicksKey is the Redis counter of userID's redirects through a link.
+// It is keyed by the link's id, which unlike its short code never changes or
+// passes to another link.
+func ViewerClicksKey(shortlinkID int, userID int64) string {
+	return "viewers:link:" + strconv.Itoa(shortlinkID) + ":user:" + strconv.FormatInt(userID, 10)
+}
+
+func parseViewerClicksKey(key string) (int, int64, bool) {
+	rest, ok := strings.CutPrefix(key, "viewers:link:")
+	if !ok {
+		return 0, 0, false
+	}
+	link, user, ok := strings.Cut(rest, ":user:")
+	if !ok {
+		return 0, 0, false
+	}
+	shortlinkID, err := strconv.Atoi(link)
+	if err != nil {
+		return 0, 0, false
+	}
+	userID, err := strconv.ParseInt(user, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return shortlinkID, userID, true
+}
@@ -0,0 +1,328 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"koda-shortlink/internal/models"
+	"koda-shortlink/internal/utils"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultWebhookPollInterval = 2 * time.Second
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookLogRetention = 30 * 24 * time.Hour
+	webhookBatchSize           = 20
+	webhookTimeout             = 10 * time.Second
+	webhookLease               = time.Minute
+	webhookBaseBackoff         = 30 * time.Second
+	webhookMaxBackoff          = 6 * time.Hour
+	webhookResponseBodyLimit   = 2048
+	webhookPurgeInterval       = time.Hour
+	webhookSubscriptionsTTL    = 10 * time.Minute
+	defaultClickMilestoneList  = "100,1000,10000,100000,1000000"
+)
+
+// clickMilestones are the redirect counts that fire link.milestone, read from
+// WEBHOOK_CLICK_MILESTONES once the environment has been loaded.
+var clickMilestones = sync.OnceValue(func() []int {
+	value := os.Getenv("WEBHOOK_CLICK_MILESTONES")
+	if value == "" {
+		value = defaultClickMilestoneList
+	}
+	var milestones []int
+	for _, s := range strings.Split(value, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && n > 0 {
+			milestones = append(milestones, n)
+		}
+	}
+	return milestones
+})
+
+func webhookSubscriptionsKey(userID int64) string {
+	return fmt.Sprintf("webhooks:user:%d:events", userID)
+}
+
+// ForgetWebhookSubscriptions drops the cached events of the user's webhooks
+// after they change.
+func ForgetWebhookSubscriptions(userID int64) {
+	utils.RedisClient.Del(context.Background(), webhookSubscriptionsKey(userID))
+}
+
+// subscribedWebhookEvents caches the events a user's webhooks listen to, so
+// clicks of users without webhooks don't hit the database.
+func subscribedWebhookEvents(db *pgxpool.Pool, userID int64) ([]string, error) {
+	rctx := context.Background()
+	key := webhookSubscriptionsKey(userID)
+
+	if val, err := utils.RedisClient.Get(rctx, key).Result(); err == nil {
+		if val == "" {
+			return nil, nil
+		}
+		return strings.Split(val, ","), nil
+	}
+
+	events, err := models.GetSubscribedWebhookEvents(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	utils.RedisClient.Set(rctx, key, strings.Join(events, ","), webhookSubscriptionsTTL)
+	return events, nil
+}
+
+// EmitWebhookEvent queues event for the user's webhooks subscribed to it.
+// Failures are logged.
+func EmitWebhookEvent(db *pgxpool.Pool, userID int64, event string, data any) {
+	events, err := subscribedWebhookEvents(db, userID)
+	if err != nil {
+		log.Printf("webhooks: subscriptions of user %d: %v", userID, err)
+		return
+	}
+	if slices.Contains(events, event) {
+		enqueueWebhookEvent(db, userID, event, data)
+	}
+}
+
+func enqueueWebhookEvent(db *pgxpool.Pool, userID int64, event string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("webhooks: encode %s: %v", event, err)
+		return
+	}
+	if _, err := models.EnqueueWebhookEvent(db, userID, event, payload); err != nil {
+		log.Printf("webhooks: enqueue %s for user %d: %v", event, userID, err)
+	}
+}
+
+// emitClickEvents sends link.clicked for the clicks stored and link.milestone
+// for every click of batch that brought its link's redirect_count to a
+// milestone, stored or not. Each count is reached by exactly one click, so a
+// milestone fires once.
+func emitClickEvents(db *pgxpool.Pool, batch, stored []ClickEvent) {
+	subscriptions := make(map[int64][]string)
+	subscribed := func(userID int64, event string) bool {
+		events, ok := subscriptions[userID]
+		if !ok {
+			var err error
+			if events, err = subscribedWebhookEvents(db, userID); err != nil {
+				log.Printf("webhooks: subscriptions of user %d: %v", userID, err)
+			}
+			subscriptions[userID] = events
+		}
+		return slices.Contains(events, event)
+	}
+
+	for _, event := range stored {
+		if event.OwnerID == nil || !subscribed(*event.OwnerID, models.WebhookEventLinkClicked) {
+			continue
+		}
+		data := map[string]any{
+			"shortlinkId":    event.Click.ShortlinkID,
+			"shortCode":      event.ShortCode,
+			"clickedAt":      event.Click.CreatedAt,
+			"countryCode":    event.Click.CountryCode,
+			"device":         event.Click.Device,
+			"browser":        event.Click.Browser,
+			"os":             event.Click.OS,
+			"referrerDomain": event.Click.ReferrerDomain,
+			"bot":            event.Click.IsBot,
+		}
+		if event.Total > 0 {
+			data["total"] = event.Total
+		}
+		enqueueWebhookEvent(db, *event.OwnerID, models.WebhookEventLinkClicked, data)
+	}
+
+	for _, event := range batch {
+		if event.OwnerID == nil || event.Total == 0 || !slices.Contains(clickMilestones(), event.Total) ||
+			!subscribed(*event.OwnerID, models.WebhookEventLinkMilestone) {
+			continue
+		}
+		enqueueWebhookEvent(db, *event.OwnerID, models.WebhookEventLinkMilestone, map[string]any{
+			"shortlinkId": event.Click.ShortlinkID,
+			"shortCode":   event.ShortCode,
+			"milestone":   event.Total,
+			"reachedAt":   event.Click.CreatedAt,
+		})
+	}
+}
+
+// webhookEnvelope is the JSON body receivers get. id is the delivery id, so
+// it stays the same across retries of one delivery.
+type webhookEnvelope struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type webhookDispatcher struct {
+	db          *pgxpool.Pool
+	client      *http.Client
+	maxAttempts int
+}
+
+// InitWebhookDispatcher sends queued webhook deliveries. WEBHOOK_POLL_INTERVAL
+// sets how often it looks for due deliveries, WEBHOOK_MAX_ATTEMPTS when it
+// gives up on one and WEBHOOK_LOG_RETENTION how long finished deliveries are
+// kept in the log.
+func InitWebhookDispatcher(db *pgxpool.Pool) {
+	d := &webhookDispatcher{
+		db:          db,
+		client:      utils.NewWebhookHTTPClient(webhookTimeout),
+		maxAttempts: envInt("WEBHOOK_MAX_ATTEMPTS", defaultWebhookMaxAttempts),
+	}
+	interval := envDuration("WEBHOOK_POLL_INTERVAL", defaultWebhookPollInterval)
+	retention := envDuration("WEBHOOK_LOG_RETENTION", defaultWebhookLogRetention)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		lastPurge := time.Time{}
+		for range ticker.C {
+			// Keep going while full batches come back so a backlog drains
+			// faster than one batch per tick.
+			for {
+				n, err := d.dispatch()
+				if err != nil {
+					log.Printf("webhooks: %v", err)
+				}
+				if err != nil || n < webhookBatchSize {
+					break
+				}
+			}
+
+			if time.Since(lastPurge) >= webhookPurgeInterval {
+				if _, err := models.PurgeWebhookDeliveries(db, retention); err != nil {
+					log.Printf("webhooks: purge: %v", err)
+				}
+				lastPurge = time.Now()
+			}
+		}
+	}()
+}
+
+// dispatch claims one batch of due deliveries and sends them concurrently.
+func (d *webhookDispatcher) dispatch() (int, error) {
+	claimed, err := models.ClaimWebhookDeliveries(d.db, webhookBatchSize, d.maxAttempts, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range claimed {
+		wg.Add(1)
+		go func(delivery models.PendingWebhookDelivery) {
+			defer wg.Done()
+			attempt := d.send(delivery)
+			if err := models.RecordWebhookAttempt(d.db, delivery.ID, attempt); err != nil {
+				log.Printf("webhooks: record delivery %d: %v", delivery.ID, err)
+				reason := "could not record the attempt: " + err.Error()
+				if err := models.FinishWebhookDelivery(d.db, delivery.ID, attempt.Delivered, reason); err != nil {
+					log.Printf("webhooks: finish delivery %d: %v", delivery.ID, err)
+				}
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(claimed), nil
+}
+
+func (d *webhookDispatcher) send(delivery models.PendingWebhookDelivery) models.WebhookAttempt {
+	body, err := json.Marshal(webhookEnvelope{
+		ID:        strconv.FormatInt(delivery.ID, 10),
+		Event:     delivery.Event,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return models.WebhookAttempt{Error: err.Error()}
+	}
+
+	// Checked again because DNS may have changed since the webhook was saved;
+	// the client's dialer guards the connection itself.
+	if err := utils.CheckWebhookURL(context.Background(), delivery.URL); err != nil {
+		if errors.Is(err, utils.ErrWebhookTargetNotAllowed) {
+			return models.WebhookAttempt{Error: err.Error()}
+		}
+		return d.retry(delivery, models.WebhookAttempt{Error: err.Error()})
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return models.WebhookAttempt{Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "koda-shortlink-webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(utils.WebhookSignatureHeader, utils.SignWebhookPayload(delivery.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return d.retry(delivery, models.WebhookAttempt{Error: err.Error()})
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	status := resp.StatusCode
+	attempt := models.WebhookAttempt{ResponseStatus: &status, ResponseBody: webhookResponseText(respBody)}
+	if status >= 200 && status < 300 {
+		attempt.Delivered = true
+		return attempt
+	}
+
+	attempt.Error = fmt.Sprintf("receiver answered %d", status)
+	return d.retry(delivery, attempt)
+}
+
+// webhookResponseText drops a character cut by webhookResponseBodyLimit, so
+// the stored excerpt ends on a rune boundary. Invalid UTF-8 and NUL bytes are
+// cleaned when the attempt is recorded.
+func webhookResponseText(body []byte) string {
+	for i := 1; i < utf8.UTFMax && i <= len(body); i++ {
+		r := body[len(body)-i]
+		if !utf8.RuneStart(r) {
+			continue
+		}
+		if !utf8.FullRune(body[len(body)-i:]) {
+			body = body[:len(body)-i]
+		}
+		break
+	}
+	return string(body)
+}
+
+// retry schedules the next attempt with exponential backoff, or leaves
+// RetryIn at zero once the delivery is out of attempts.
+func (d *webhookDispatcher) retry(delivery models.PendingWebhookDelivery, attempt models.WebhookAttempt) models.WebhookAttempt {
+	if delivery.Attempts < d.maxAttempts {
+		attempt.RetryIn = webhookBackoff(delivery.Attempts)
+	}
+	return attempt
+}
+
+// webhookBackoff doubles the wait after every failed attempt: 30s, 1m, 2m,
+// ... up to webhookMaxBackoff.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
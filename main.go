@@ -10,9 +10,19 @@ package main
 // @in header
 // @name Authorization
 import (
+	"context"
 	"koda-shortlink/internal/config"
+	"koda-shortlink/internal/handler"
 	"koda-shortlink/internal/routers"
 	"koda-shortlink/internal/utils"
+	"koda-shortlink/internal/worker"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	_ "koda-shortlink/docs"
 
@@ -27,6 +37,40 @@ func main() {
 	r := routers.InitRouter(pg)
 
 	utils.InitRedis()
+	utils.InitShortCodeGenerator()
+	utils.InitGeoIP()
+	utils.InitMailer()
+	worker.InitClickPipeline(pg)
+	worker.InitViewerClickFlusher(pg)
+	worker.InitDailyStatsAggregator(pg)
+	worker.InitClickPartitionManager(pg)
+	worker.InitWebhookDispatcher(pg)
+	worker.InitSessionPurger(pg)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	r.Run(":8082")
+
+	srv := &http.Server{Addr: ":8082", Handler: r}
+	srv.RegisterOnShutdown(handler.CloseStreams)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	// Stop taking requests first so no click arrives after the pipeline
+	// has been drained.
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown: %v", err)
+	}
+	if err := worker.Clicks.Close(ctx); err != nil {
+		log.Println(err)
+	}
+	if err := worker.FlushViewerClicks(pg); err != nil {
+		log.Printf("viewer clicks: %v", err)
+	}
 }
\ No newline at end of file
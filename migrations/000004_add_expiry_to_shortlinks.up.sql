@@ -0,0 +1,3 @@
+ALTER TABLE shortlinks
+ADD COLUMN expires_at TIMESTAMP,
+ADD COLUMN max_clicks INT CHECK (max_clicks > 0);
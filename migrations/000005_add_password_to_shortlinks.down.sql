@@ -0,0 +1,2 @@
+ALTER TABLE shortlinks
+DROP COLUMN IF EXISTS password_hash;
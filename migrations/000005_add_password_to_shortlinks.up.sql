@@ -0,0 +1,2 @@
+ALTER TABLE shortlinks
+ADD COLUMN password_hash TEXT;
@@ -0,0 +1,6 @@
+ALTER TABLE shortlink_clicks
+DROP COLUMN IF EXISTS device,
+DROP COLUMN IF EXISTS os,
+DROP COLUMN IF EXISTS browser,
+DROP COLUMN IF EXISTS referrer_domain,
+DROP COLUMN IF EXISTS referrer;
@@ -0,0 +1,6 @@
+ALTER TABLE shortlink_clicks
+ADD COLUMN referrer TEXT,
+ADD COLUMN referrer_domain VARCHAR(255),
+ADD COLUMN browser VARCHAR(50),
+ADD COLUMN os VARCHAR(50),
+ADD COLUMN device VARCHAR(20);
@@ -0,0 +1,4 @@
+ALTER TABLE shortlink_clicks
+DROP COLUMN IF EXISTS city,
+DROP COLUMN IF EXISTS region_code,
+DROP COLUMN IF EXISTS country_code;
@@ -0,0 +1,4 @@
+ALTER TABLE shortlink_clicks
+ADD COLUMN country_code VARCHAR(2),
+ADD COLUMN region_code VARCHAR(10),
+ADD COLUMN city VARCHAR(100);
@@ -0,0 +1,2 @@
+ALTER TABLE shortlink_clicks
+DROP COLUMN IF EXISTS is_bot;
@@ -0,0 +1,2 @@
+ALTER TABLE shortlink_clicks
+ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS shortlink_viewer_clicks;
@@ -0,0 +1,10 @@
+CREATE TABLE shortlink_viewer_clicks (
+    shortlink_id INT NOT NULL REFERENCES shortlinks(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    clicks INT NOT NULL DEFAULT 0,
+    first_clicked_at TIMESTAMP DEFAULT now(),
+    last_clicked_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (shortlink_id, user_id)
+);
+
+CREATE INDEX idx_shortlink_viewer_clicks_user_id ON shortlink_viewer_clicks(user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_shortlink_clicks_clicked_at;
+DROP TABLE IF EXISTS shortlink_rollup_state;
+DROP TABLE IF EXISTS shortlink_daily_stats;
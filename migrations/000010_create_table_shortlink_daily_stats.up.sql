@@ -0,0 +1,23 @@
+CREATE TABLE shortlink_daily_stats (
+    shortlink_id INT NOT NULL REFERENCES shortlinks(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    clicks INT NOT NULL DEFAULT 0,
+    uniques INT NOT NULL DEFAULT 0,
+    bots INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (shortlink_id, day)
+);
+
+CREATE INDEX idx_shortlink_daily_stats_day ON shortlink_daily_stats(day);
+
+-- Days before rolled_up_to are complete in shortlink_daily_stats; NULL means
+-- nothing has been rolled up yet.
+CREATE TABLE shortlink_rollup_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    rolled_up_to DATE,
+    updated_at TIMESTAMP DEFAULT now()
+);
+
+INSERT INTO shortlink_rollup_state (id, rolled_up_to) VALUES (TRUE, NULL);
+
+CREATE INDEX IF NOT EXISTS idx_shortlink_clicks_clicked_at ON shortlink_clicks(clicked_at);
@@ -0,0 +1,37 @@
+ALTER TABLE shortlink_rollup_state DROP COLUMN IF EXISTS raw_from;
+
+DROP INDEX IF EXISTS idx_shortlink_clicks_clicked_at;
+DROP INDEX IF EXISTS idx_shortlink_clicks_shortlink_id_clicked_at;
+
+ALTER TABLE shortlink_clicks RENAME TO shortlink_clicks_partitioned;
+ALTER TABLE shortlink_clicks_partitioned RENAME CONSTRAINT shortlink_clicks_pkey TO shortlink_clicks_partitioned_pkey;
+ALTER SEQUENCE shortlink_clicks_id_seq OWNED BY NONE;
+
+CREATE TABLE shortlink_clicks (
+    id INT PRIMARY KEY DEFAULT nextval('shortlink_clicks_id_seq'),
+    shortlink_id INT REFERENCES shortlinks(id) ON DELETE CASCADE,
+    ip_address VARCHAR(50),
+    user_agent TEXT,
+    clicked_at TIMESTAMP DEFAULT now(),
+    referrer TEXT,
+    referrer_domain VARCHAR(255),
+    browser VARCHAR(50),
+    os VARCHAR(50),
+    device VARCHAR(20),
+    country_code VARCHAR(2),
+    region_code VARCHAR(10),
+    city VARCHAR(100),
+    is_bot BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+ALTER SEQUENCE shortlink_clicks_id_seq OWNED BY shortlink_clicks.id;
+
+INSERT INTO shortlink_clicks
+SELECT id, shortlink_id, ip_address, user_agent, clicked_at, referrer, referrer_domain,
+       browser, os, device, country_code, region_code, city, is_bot
+FROM shortlink_clicks_partitioned;
+
+DROP TABLE shortlink_clicks_partitioned;
+DROP FUNCTION IF EXISTS create_shortlink_clicks_partition(DATE);
+
+CREATE INDEX idx_shortlink_clicks_clicked_at ON shortlink_clicks(clicked_at);
@@ -0,0 +1,85 @@
+-- Turn shortlink_clicks into a table partitioned by month of clicked_at.
+-- The old table is renamed, its rows copied over and then dropped.
+
+DROP INDEX IF EXISTS idx_shortlink_clicks_clicked_at;
+
+ALTER TABLE shortlink_clicks RENAME TO shortlink_clicks_legacy;
+ALTER TABLE shortlink_clicks_legacy RENAME CONSTRAINT shortlink_clicks_pkey TO shortlink_clicks_legacy_pkey;
+ALTER SEQUENCE shortlink_clicks_id_seq OWNED BY NONE;
+
+CREATE TABLE shortlink_clicks (
+    id INT NOT NULL DEFAULT nextval('shortlink_clicks_id_seq'),
+    shortlink_id INT REFERENCES shortlinks(id) ON DELETE CASCADE,
+    ip_address VARCHAR(50),
+    user_agent TEXT,
+    clicked_at TIMESTAMP NOT NULL DEFAULT now(),
+    referrer TEXT,
+    referrer_domain VARCHAR(255),
+    browser VARCHAR(50),
+    os VARCHAR(50),
+    device VARCHAR(20),
+    country_code VARCHAR(2),
+    region_code VARCHAR(10),
+    city VARCHAR(100),
+    is_bot BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id, clicked_at)
+) PARTITION BY RANGE (clicked_at);
+
+ALTER SEQUENCE shortlink_clicks_id_seq OWNED BY shortlink_clicks.id;
+
+CREATE INDEX idx_shortlink_clicks_shortlink_id_clicked_at ON shortlink_clicks(shortlink_id, clicked_at);
+CREATE INDEX idx_shortlink_clicks_clicked_at ON shortlink_clicks(clicked_at);
+
+-- Catches clicks outside every monthly partition, so inserts never fail
+-- when the partition creator falls behind.
+CREATE TABLE shortlink_clicks_default PARTITION OF shortlink_clicks DEFAULT;
+
+-- create_shortlink_clicks_partition creates the partition holding the month
+-- of p_month, named shortlink_clicks_pYYYYMM, unless it already exists.
+CREATE OR REPLACE FUNCTION create_shortlink_clicks_partition(p_month DATE)
+RETURNS TEXT AS $$
+DECLARE
+    month_start DATE := date_trunc('month', p_month)::date;
+    partition_name TEXT := 'shortlink_clicks_p' || to_char(month_start, 'YYYYMM');
+BEGIN
+    IF to_regclass(partition_name) IS NULL THEN
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF shortlink_clicks FOR VALUES FROM (%L) TO (%L)',
+            partition_name, month_start, (month_start + interval '1 month')::date
+        );
+    END IF;
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+DECLARE
+    m DATE;
+BEGIN
+    FOR m IN
+        SELECT generate_series(
+            date_trunc('month', COALESCE((SELECT MIN(clicked_at) FROM shortlink_clicks_legacy), now())),
+            date_trunc('month', now()) + interval '2 months',
+            interval '1 month'
+        )::date
+    LOOP
+        PERFORM create_shortlink_clicks_partition(m);
+    END LOOP;
+END;
+$$;
+
+INSERT INTO shortlink_clicks (
+    id, shortlink_id, ip_address, user_agent, clicked_at, referrer, referrer_domain,
+    browser, os, device, country_code, region_code, city, is_bot
+)
+SELECT id, shortlink_id, ip_address, user_agent, COALESCE(clicked_at, now()), referrer, referrer_domain,
+       browser, os, device, country_code, region_code, city, is_bot
+FROM shortlink_clicks_legacy;
+
+DROP TABLE shortlink_clicks_legacy;
+
+-- Raw clicks before raw_from have been dropped or archived by the retention
+-- policy; their days only exist in shortlink_daily_stats.
+ALTER TABLE shortlink_rollup_state ADD COLUMN raw_from DATE;
+
+CREATE SCHEMA IF NOT EXISTS archive;
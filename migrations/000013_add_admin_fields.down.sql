@@ -0,0 +1,5 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS suspended_reason,
+DROP COLUMN IF EXISTS suspended_at,
+ALTER COLUMN role DROP NOT NULL,
+ALTER COLUMN role DROP DEFAULT;
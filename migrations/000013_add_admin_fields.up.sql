@@ -0,0 +1,10 @@
+-- Roles used to be chosen at registration, so no existing admin can be
+-- trusted. Everyone becomes a regular user; real admins are promoted again
+-- with cmd/setrole.
+UPDATE users SET role = 'user' WHERE role IS NULL OR role <> 'user';
+
+ALTER TABLE users
+ALTER COLUMN role SET DEFAULT 'user',
+ALTER COLUMN role SET NOT NULL,
+ADD COLUMN suspended_at TIMESTAMP,
+ADD COLUMN suspended_reason TEXT;
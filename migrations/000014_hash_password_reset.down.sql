@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_reset_token;
+
+UPDATE users SET reset_token = NULL, reset_otp = NULL, reset_expires = NULL;
+ALTER TABLE users ALTER COLUMN reset_otp TYPE VARCHAR(25);
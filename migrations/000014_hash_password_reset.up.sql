@@ -0,0 +1,4 @@
+-- reset_token and reset_otp hold SHA-256 hex digests from now on.
+ALTER TABLE users ALTER COLUMN reset_otp TYPE VARCHAR(64);
+
+CREATE INDEX idx_users_reset_token ON users(reset_token) WHERE reset_token IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- Accounts created before verification existed are treated as verified so
+-- they keep being able to create links.
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_sessions_family_id;
+
+-- Hashed tokens can't be turned back into tokens, so everyone logs in again.
+DELETE FROM sessions;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS rotated_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS family_id;
+
+ALTER TABLE sessions ALTER COLUMN refresh_token_hash TYPE TEXT;
+ALTER TABLE sessions RENAME COLUMN refresh_token_hash TO refresh_token;
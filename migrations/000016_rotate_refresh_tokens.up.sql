@@ -0,0 +1,10 @@
+-- Refresh tokens are kept as SHA-256 hex digests. A login starts a token
+-- family; every refresh rotates the token within it and marks the old one.
+ALTER TABLE sessions RENAME COLUMN refresh_token TO refresh_token_hash;
+UPDATE sessions SET refresh_token_hash = encode(sha256(convert_to(refresh_token_hash, 'UTF8')), 'hex');
+ALTER TABLE sessions ALTER COLUMN refresh_token_hash TYPE VARCHAR(64);
+
+ALTER TABLE sessions ADD COLUMN family_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE sessions ADD COLUMN rotated_at TIMESTAMP;
+
+CREATE INDEX idx_sessions_family_id ON sessions(family_id);
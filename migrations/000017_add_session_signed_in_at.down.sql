@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sessions_expires_at;
+DROP INDEX IF EXISTS idx_sessions_user_id;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS signed_in_at;
@@ -0,0 +1,13 @@
+-- signed_in_at is the login time of the token family; created_at of the
+-- family's current row is when it was last refreshed.
+ALTER TABLE sessions ADD COLUMN signed_in_at TIMESTAMP NOT NULL DEFAULT now();
+
+UPDATE sessions s SET signed_in_at = f.first_created_at
+FROM (
+    SELECT family_id, COALESCE(MIN(created_at), now()) AS first_created_at
+    FROM sessions GROUP BY family_id
+) f
+WHERE s.family_id = f.family_id;
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id) WHERE rotated_at IS NULL;
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
@@ -0,0 +1,41 @@
+-- Postgres refuses to create a partition for a month the default partition
+-- already holds rows of, which left create_shortlink_clicks_partition failing
+-- for that month for good. The rows are now moved out of the default
+-- partition into the new one before it is attached.
+CREATE OR REPLACE FUNCTION create_shortlink_clicks_partition(p_month DATE)
+RETURNS TEXT AS $$
+DECLARE
+    month_start DATE := date_trunc('month', p_month)::date;
+    month_end DATE := (date_trunc('month', p_month) + interval '1 month')::date;
+    partition_name TEXT := 'shortlink_clicks_p' || to_char(month_start, 'YYYYMM');
+BEGIN
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN partition_name;
+    END IF;
+
+    -- Keeps new clicks of the month from landing in the default partition
+    -- between the move and the attach.
+    LOCK TABLE shortlink_clicks_default IN EXCLUSIVE MODE;
+
+    IF EXISTS (SELECT 1 FROM shortlink_clicks_default WHERE clicked_at >= month_start AND clicked_at < month_end) THEN
+        EXECUTE format('CREATE TABLE %I (LIKE shortlink_clicks INCLUDING DEFAULTS)', partition_name);
+        EXECUTE format(
+            'WITH moved AS (
+                 DELETE FROM shortlink_clicks_default WHERE clicked_at >= %L AND clicked_at < %L RETURNING *
+             )
+             INSERT INTO %I SELECT * FROM moved',
+            month_start, month_end, partition_name
+        );
+        EXECUTE format(
+            'ALTER TABLE shortlink_clicks ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)',
+            partition_name, month_start, month_end
+        );
+    ELSE
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF shortlink_clicks FOR VALUES FROM (%L) TO (%L)',
+            partition_name, month_start, month_end
+        );
+    END IF;
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql;